@@ -5,5 +5,5 @@ import (
 )
 
 func init() {
-	RootCmd.AddCommand(apply.Command())
+	RootCmd.AddCommand(apply.Command(), apply.ImportCommand())
 }
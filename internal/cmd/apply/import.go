@@ -0,0 +1,85 @@
+package apply
+
+import (
+	"fmt"
+	"strings"
+
+	"qs-tools/internal/component"
+	"qs-tools/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// ImportCommand 返回从离线包恢复的命令
+func ImportCommand() *cobra.Command {
+	return ImportCmd
+}
+
+// ImportCmd 表示 import 命令
+var ImportCmd = &cobra.Command{
+	Use:   "import <file> [component...]",
+	Short: "从离线包恢复配置",
+	Long: `从 "qs-tools export" 导出的离线包（.qsb）恢复配置，不需要连接远程服务器。
+未指定组件时恢复离线包中所有支持当前系统的组件。
+
+支持与 apply 相同的选项，如 --dry-run、--conflict、--only、--target。`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importBundle(args[0], args[1:])
+	},
+}
+
+// importBundle 从离线包中依次恢复组件，恢复后不更新与远程服务器的同步记录
+func importBundle(path string, names []string) error {
+	b, err := utils.OpenBundle(path)
+	if err != nil {
+		return err
+	}
+	info := b.Info()
+	fmt.Printf("离线包来自 %s，导出于 %s，包含: %s\n\n", info.Hostname,
+		info.CreatedAt.Local().Format("2006-01-02 15:04:05"), strings.Join(b.Components(), ", "))
+
+	var selected []component.Component
+	if len(names) == 0 {
+		for _, name := range b.Components() {
+			c, ok := component.Get(name)
+			if !ok || !component.Supports(c, component.OpApply) {
+				fmt.Printf("⚠️ 跳过 %s: 当前没有该组件\n", name)
+				continue
+			}
+			if err := component.CheckPlatform(c); err != nil {
+				fmt.Printf("⚠️ 跳过 %s: %v\n", name, err)
+				continue
+			}
+			selected = append(selected, c)
+		}
+	} else {
+		inBundle := make(map[string]bool)
+		for _, name := range b.Components() {
+			inBundle[name] = true
+		}
+		for _, name := range names {
+			if !inBundle[name] {
+				return fmt.Errorf("离线包中没有 %s 的备份", name)
+			}
+			c, ok := component.Get(name)
+			if !ok || !component.Supports(c, component.OpApply) {
+				return fmt.Errorf("不支持的组件: %s", name)
+			}
+			selected = append(selected, c)
+		}
+	}
+
+	var failed []string
+	for _, c := range selected {
+		if err := applyComponent(c, b); err != nil {
+			fmt.Printf("\n❌ %s 恢复失败: %v\n", c.Description(), err)
+			failed = append(failed, c.Name())
+		}
+		fmt.Println()
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d 个组件恢复失败: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -2,6 +2,13 @@ package apply
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
+
+	"qs-tools/internal/component"
+	"qs-tools/internal/config"
+	"qs-tools/internal/utils"
+
 	"github.com/spf13/cobra"
 )
 
@@ -10,42 +17,196 @@ func Command() *cobra.Command {
 	return ApplyCmd
 }
 
+// applyOptions 恢复选项
+var applyOptions utils.ApplyOptions
+
+// conflictPolicy 命令行指定的冲突处理策略，为空时使用配置文件中组件的设置
+var conflictPolicy string
+
+// applyAt 命令行指定的恢复时间
+var applyAt string
+
 // ApplyCmd 表示 apply 命令
 var ApplyCmd = &cobra.Command{
 	Use:   "apply [component]",
 	Short: "从远程服务器恢复配置",
-	Long: `从远程服务器下载并恢复之前备份的配置文件。
+	Long: fmt.Sprintf(`从远程服务器下载并恢复之前备份的配置文件。
 目前支持的组件：
-  - fish: 恢复 Fish Shell 配置
-  - scoop: 恢复 Scoop 包管理器配置 (Windows)
-  - nvim: 恢复 Neovim 编辑器配置
+%s
+
+使用 --dry-run 可以在恢复前查看新增、修改和删除的文件以及文本文件的差异，
+不会修改本地文件。
+
+上一次恢复后在本地修改过的文件可以通过 --conflict 或配置文件中组件的 conflict
+指定处理策略：
+  - overwrite: 用备份覆盖（默认）
+  - keep-local: 保留本地文件
+  - keep-both: 保留本地文件，备份中的文件加上 .qs-remote 后缀另存
+  - newer-wins: 保留修改时间较新的文件
+  - prompt: 逐个文件询问
+
+默认恢复最新的备份，可以通过 --version 指定版本号或标签（备份时通过 --tag 添加），
+或通过 --at 恢复某个时间之前最新的备份，版本列表见 "qs-tools backup list <component>"。
+
+使用 --only 只恢复部分文件，如 --only 'lua/plugins/' 或 --only '*.fish'，
+模式可以匹配文件路径、所在目录或文件名；使用 --target 恢复到其他目录查看，
+不会修改当前的配置。
 
 支持的系统：
   - Ubuntu 及衍生版
   - Debian 及衍生版
   - Kylin (银河麒麟)
-  - Windows`,
-	Run: func(cmd *cobra.Command, args []string) {
+  - Windows`, component.HelpList(component.OpApply, "恢复 %s 配置")),
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
 			fmt.Println("请指定要恢复的组件")
-			return
+			return nil
 		}
 
-		var err error
-		switch args[0] {
-		case "fish":
-			err = applyFish()
-		case "scoop":
-			err = applyScoop()
-		case "nvim":
-			err = applyNvim()
-		default:
+		// 配置文件中声明的组件在执行命令前才加载，没有子命令，在这里恢复
+		c, ok := component.Get(args[0])
+		if !ok || !component.Supports(c, component.OpApply) {
 			fmt.Printf("不支持的组件: %s\n", args[0])
-			return
+			return nil
 		}
+		return applyComponent(c, utils.RemoteStore())
+	},
+}
+
+func init() {
+	ApplyCmd.PersistentFlags().BoolVar(&applyOptions.DryRun, "dry-run", false, "只显示恢复会带来的文件变化，不修改本地文件")
+	ApplyCmd.PersistentFlags().StringVar(&conflictPolicy, "conflict", "",
+		fmt.Sprintf("本地有修改的文件的处理策略 (%s)", strings.Join(utils.ConflictPolicyNames(), "|")))
+	ApplyCmd.PersistentFlags().StringVar(&applyOptions.Version, "version", "", "恢复指定版本号或标签的备份，默认恢复最新的备份")
+	ApplyCmd.PersistentFlags().StringVar(&applyAt, "at", "", "恢复指定时间之前最新的备份，如 2026-10-01 或 \"2026-10-01 18:30\"")
+	ApplyCmd.PersistentFlags().StringSliceVar(&applyOptions.Only, "only", nil, "只恢复匹配的文件或目录，glob 模式，可以指定多次")
+	ApplyCmd.PersistentFlags().StringVar(&applyOptions.Target, "target", "", "恢复到指定目录而不是配置所在的位置")
+
+	// 从离线包恢复时使用相同的恢复选项
+	ImportCmd.Flags().AddFlagSet(ApplyCmd.PersistentFlags())
+
+	// 为每个组件生成子命令
+	for _, c := range component.All(component.OpApply) {
+		ApplyCmd.AddCommand(newComponentCmd(c))
+	}
+}
+
+// newComponentCmd 生成恢复组件的子命令，有多份配置的组件可以通过参数指定配置
+func newComponentCmd(c component.Component) *cobra.Command {
+	if component.HasProfiles(c) {
+		return newProfileCmd(c)
+	}
+	return &cobra.Command{
+		Use:   c.Name(),
+		Short: fmt.Sprintf("恢复 %s 配置", c.Description()),
+		Long:  fmt.Sprintf("从远程服务器下载并恢复 %s 的配置文件。", c.Description()),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return applyComponent(c, utils.RemoteStore())
+		},
+	}
+}
 
+// newProfileCmd 生成恢复有多份配置的组件的子命令，未指定配置时使用默认的配置
+func newProfileCmd(c component.Component) *cobra.Command {
+	return &cobra.Command{
+		Use:   c.Name() + " [profile]",
+		Short: fmt.Sprintf("恢复 %s 配置", c.Description()),
+		Long: fmt.Sprintf(`从远程服务器下载并恢复 %s 的配置文件。
+可以指定要恢复的配置，未指定时使用默认的配置，
+通过 "qs-tools %s list" 查看全部配置。`, c.Description(), c.Name()),
+		Args: cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			profiles, _ := component.Profiles(c)
+			return profiles, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+			pc, err := component.WithProfile(c, name)
+			if err != nil {
+				return err
+			}
+			return applyComponent(pc, utils.RemoteStore())
+		},
+	}
+}
+
+// applyComponent 从 store 中恢复组件的配置
+func applyComponent(c component.Component, store utils.Store) error {
+	if err := component.CheckPlatform(c); err != nil {
+		return err
+	}
+
+	opts := applyOptions
+	opts.Store = store
+	policy, err := resolveConflictPolicy(c)
+	if err != nil {
+		return err
+	}
+	opts.Conflict = policy
+
+	if applyAt != "" {
+		if opts.Version != "" {
+			return fmt.Errorf("--at 和 --version 不能同时使用")
+		}
+		if opts.At, err = utils.ParseBackupTime(applyAt); err != nil {
+			return err
+		}
+	}
+
+	if opts.Target != "" {
+		target, err := filepath.Abs(opts.Target)
 		if err != nil {
-			fmt.Printf("恢复失败: %v\n", err)
+			return fmt.Errorf("无效的目录 %s: %v", opts.Target, err)
 		}
-	},
+		opts.Target = target
+	}
+	if len(opts.Only) > 0 {
+		fmt.Printf("只恢复匹配 %s 的文件\n", strings.Join(opts.Only, ", "))
+	}
+
+	if opts.DryRun {
+		fmt.Printf("预览恢复 %s 配置的变化...\n", c.Description())
+		if err := component.Apply(c, opts); err != nil {
+			return err
+		}
+		fmt.Println("\n预览完成，没有修改任何本地文件")
+		return nil
+	}
+
+	fmt.Printf("开始恢复 %s 配置...\n", c.Description())
+
+	if err := component.Apply(c, opts); err != nil {
+		return err
+	}
+
+	if opts.Target != "" {
+		fmt.Printf("\n✅ %s 配置已恢复到 %s\n", c.Description(), opts.Target)
+		return nil
+	}
+	fmt.Printf("\n✅ %s 配置恢复成功！\n", c.Description())
+	return nil
+}
+
+// resolveConflictPolicy 返回组件的冲突处理策略，命令行参数优先于配置文件
+func resolveConflictPolicy(c component.Component) (utils.ConflictPolicy, error) {
+	if conflictPolicy != "" {
+		return utils.ParseConflictPolicy(conflictPolicy)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	policy, err := utils.ParseConflictPolicy(cfg.Component(c.Name()).Conflict)
+	if err != nil {
+		return "", fmt.Errorf("配置文件中 %s 的 conflict 无效: %v", c.Name(), err)
+	}
+	return policy, nil
 }
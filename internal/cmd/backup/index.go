@@ -2,6 +2,11 @@ package backup
 
 import (
 	"fmt"
+	"strings"
+
+	"qs-tools/internal/component"
+	"qs-tools/internal/config"
+	"qs-tools/internal/utils"
 
 	"github.com/spf13/cobra"
 )
@@ -11,42 +16,181 @@ func Command() *cobra.Command {
 	return BackupCmd
 }
 
+// backupOptions 备份选项
+var backupOptions utils.BackupOptions
+
+// secretPolicy 命令行指定的密钥处理策略，为空时使用配置文件中的设置
+var secretPolicy string
+
+// backupAll 备份当前系统上所有有本地配置的组件
+var backupAll bool
+
 // BackupCmd 表示备份命令
 var BackupCmd = &cobra.Command{
 	Use:   "backup [component]",
 	Short: "备份配置文件",
-	Long: `备份配置文件并上传到远程服务器。
+	Long: fmt.Sprintf(`备份配置文件并上传到远程服务器。
 目前支持的组件：
-  - fish: 备份 Fish Shell 配置
-  - scoop: 备份 Scoop 包管理器配置 (Windows)
-  - nvim: 备份 Neovim 编辑器配置
+%s
+
+每次备份都会在远程服务器上保存为一个新版本，并在备份中附带清单，
+可以通过 "qs-tools backup show <component> [version]" 查看。
+已有备份时默认只上传有变化的文件，连续增量备份达到一定次数后
+会自动进行全量备份，也可以通过 --full 强制进行全量备份。
+配置与最新的备份相同时会跳过上传，可以通过 --force 强制上传。
+可以通过 --tag 为备份添加标签，同一组件中标签只属于最近添加的版本，
+通过 "qs-tools backup list <component>" 查看全部版本。
+使用 --all 备份当前系统上所有有本地配置的组件。
+
+上传前会扫描 AWS 密钥、GitHub Token、私钥和疑似密钥的赋值，发现时默认取消备份，
+可以通过 --secrets 或配置文件中的 secrets 指定为 redact（脱敏后上传）或 warn（只警告）。
 
 支持的系统：
   - Ubuntu 及衍生版
   - Debian 及衍生版
   - Kylin (银河麒麟)
-  - Windows`,
-	Run: func(cmd *cobra.Command, args []string) {
+  - Windows`, component.HelpList(component.OpBackup, "备份 %s 配置")),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if backupAll {
+			return backupAllComponents()
+		}
 		if len(args) == 0 {
 			fmt.Println("请指定要备份的组件")
-			return
+			return nil
 		}
 
-		var err error
-		switch args[0] {
-		case "fish":
-			err = backupFish()
-		case "scoop":
-			err = backupScoop()
-		case "nvim":
-			err = backupNvim()
-		default:
+		// 配置文件中声明的组件在执行命令前才加载，没有子命令，在这里备份
+		c, ok := component.Get(args[0])
+		if !ok || !component.Supports(c, component.OpBackup) {
 			fmt.Printf("不支持的组件: %s\n", args[0])
-			return
+			return nil
+		}
+		return backupComponent(c)
+	},
+}
+
+func init() {
+	BackupCmd.PersistentFlags().BoolVar(&backupOptions.Full, "full", false, "强制进行全量备份")
+	BackupCmd.PersistentFlags().BoolVar(&backupOptions.Force, "force", false, "配置没有变化时也上传新的备份")
+	BackupCmd.PersistentFlags().StringVar(&backupOptions.Tag, "tag", "", "为备份添加标签，恢复时可以通过 --version <tag> 指定")
+	BackupCmd.Flags().BoolVar(&backupAll, "all", false, "备份当前系统上所有有本地配置的组件")
+	BackupCmd.PersistentFlags().StringVar(&secretPolicy, "secrets", "",
+		fmt.Sprintf("发现疑似密钥时的处理策略 (%s)，默认取消备份", strings.Join(utils.SecretPolicyNames(), "|")))
+
+	// 为每个组件生成子命令
+	for _, c := range component.All(component.OpBackup) {
+		BackupCmd.AddCommand(newComponentCmd(c))
+	}
+}
+
+// newComponentCmd 生成备份组件的子命令，有多份配置的组件可以通过参数指定配置
+func newComponentCmd(c component.Component) *cobra.Command {
+	if component.HasProfiles(c) {
+		return newProfileCmd(c)
+	}
+	return &cobra.Command{
+		Use:   c.Name(),
+		Short: fmt.Sprintf("备份 %s 配置", c.Description()),
+		Long:  fmt.Sprintf("备份 %s 配置文件并上传到远程服务器。", c.Description()),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return backupComponent(c)
+		},
+	}
+}
+
+// newProfileCmd 生成备份有多份配置的组件的子命令，未指定配置时使用默认的配置
+func newProfileCmd(c component.Component) *cobra.Command {
+	return &cobra.Command{
+		Use:   c.Name() + " [profile]",
+		Short: fmt.Sprintf("备份 %s 配置", c.Description()),
+		Long: fmt.Sprintf(`备份 %s 配置文件并上传到远程服务器。
+可以指定要备份的配置，未指定时使用默认的配置，
+通过 "qs-tools %s list" 查看全部配置。`, c.Description(), c.Name()),
+		Args: cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			profiles, _ := component.Profiles(c)
+			return profiles, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+			pc, err := component.WithProfile(c, name)
+			if err != nil {
+				return err
+			}
+			return backupComponent(pc)
+		},
+	}
+}
+
+func backupComponent(c component.Component) error {
+	if err := component.CheckPlatform(c); err != nil {
+		return err
+	}
+
+	opts := backupOptions
+	policy, err := resolveSecretPolicy(c)
+	if err != nil {
+		return err
+	}
+	opts.Secrets = policy
+
+	fmt.Printf("开始备份 %s 配置...\n", c.Description())
+
+	result, err := component.Backup(c, opts)
+	if err != nil {
+		return err
+	}
+	if result.Unchanged {
+		fmt.Printf("\n✅ %s 配置没有变化，跳过备份。最新版本: %s\n", c.Description(), result.Meta.ID)
+		if backupOptions.Tag != "" {
+			fmt.Printf("已为版本 %s 添加标签 %s\n", result.Meta.ID, backupOptions.Tag)
 		}
+		return nil
+	}
 
-		if err != nil {
-			fmt.Printf("备份失败: %v\n", err)
+	fmt.Printf("\n✅ %s 配置备份成功！版本: %s\n", c.Description(), result.Meta.ID)
+	return nil
+}
+
+// backupAllComponents 依次备份当前系统上所有有本地配置的组件，单个组件失败不影响其他组件
+func backupAllComponents() error {
+	var failed []string
+	for _, c := range component.All(component.OpBackup) {
+		if !component.HasLocalConfig(c) {
+			continue
 		}
-	},
+		if err := backupComponent(c); err != nil {
+			fmt.Printf("\n❌ %s 备份失败: %v\n", c.Description(), err)
+			failed = append(failed, c.Name())
+		}
+		fmt.Println()
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d 个组件备份失败: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// resolveSecretPolicy 返回组件的密钥处理策略，命令行参数优先于配置文件中组件和全局的设置
+func resolveSecretPolicy(c component.Component) (utils.SecretPolicy, error) {
+	if secretPolicy != "" {
+		return utils.ParseSecretPolicy(secretPolicy)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	p, err := utils.ParseSecretPolicy(cfg.SecretPolicy(c.Name()))
+	if err != nil {
+		return "", fmt.Errorf("配置文件中的 secrets 无效: %v", err)
+	}
+	return p, nil
 }
@@ -0,0 +1,57 @@
+package backup
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"qs-tools/internal/component"
+	"qs-tools/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list <component>",
+	Short: "列出备份版本",
+	Long:  `列出组件在远程服务器上的全部备份版本，最新的版本在最后。`,
+	Args:  cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return component.Names(component.OpBackup), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listBackups(args[0])
+	},
+}
+
+func init() {
+	BackupCmd.AddCommand(listCmd)
+}
+
+func listBackups(component string) error {
+	versions, err := utils.RemoteStore().List(component)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		fmt.Printf("远程服务器上没有 %s 的备份\n", component)
+		return nil
+	}
+
+	fmt.Printf("%s 的备份版本：\n", component)
+	for _, v := range versions {
+		kind := "全量"
+		if v.Base != "" {
+			kind = "增量"
+		}
+		fmt.Printf("  %s  %s  %s  %-16s %s", v.ID, v.CreatedAt.Local().Format(time.DateTime), kind, v.Hostname, v.OS)
+		if len(v.Tags) > 0 {
+			fmt.Printf("  [%s]", strings.Join(v.Tags, ", "))
+		}
+		fmt.Println()
+	}
+	return nil
+}
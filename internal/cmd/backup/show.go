@@ -0,0 +1,87 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	"qs-tools/internal/component"
+	"qs-tools/internal/manifest"
+	"qs-tools/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var showJSON bool
+
+var showCmd = &cobra.Command{
+	Use:   "show <component> [version]",
+	Short: "查看备份清单",
+	Long: `查看远程服务器上备份的清单，包括来源主机、系统、工具版本和文件列表。
+未指定版本时显示最新的备份。`,
+	Args: cobra.RangeArgs(1, 2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return component.Names(component.OpBackup), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version := ""
+		if len(args) > 1 {
+			version = args[1]
+		}
+		return showBackup(args[0], version)
+	},
+}
+
+func init() {
+	showCmd.Flags().BoolVar(&showJSON, "json", false, "以 JSON 格式输出清单")
+	BackupCmd.AddCommand(showCmd)
+}
+
+func showBackup(component, version string) error {
+	// 只读取备份开头的清单
+	m, meta, err := utils.FetchManifest(utils.RemoteStore(), component, version)
+	if err != nil {
+		return err
+	}
+
+	if showJSON {
+		data, err := m.Marshal()
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printManifest(meta.ID, m)
+	return nil
+}
+
+func printManifest(id string, m *manifest.Manifest) {
+	fmt.Printf("\n组件:       %s\n", m.Component)
+	fmt.Printf("版本:       %s\n", id)
+	if m.Base != "" {
+		fmt.Printf("类型:       增量备份 (基于 %s)\n", m.Base)
+	} else {
+		fmt.Printf("类型:       全量备份\n")
+	}
+	fmt.Printf("备份时间:   %s\n", m.CreatedAt.Local().Format(time.DateTime))
+	fmt.Printf("主机:       %s (%s)\n", m.Hostname, m.User)
+	fmt.Printf("系统:       %s/%s\n", m.OS, m.Arch)
+	fmt.Printf("qs-tools:   %s\n", m.QSToolsVersion)
+	if m.ToolVersion != "" {
+		fmt.Printf("工具版本:   %s\n", m.ToolVersion)
+	}
+	fmt.Printf("源路径:     %s\n", m.SourcePath)
+
+	fmt.Printf("\n文件 (%d):\n", len(m.Files))
+	for _, f := range m.Files {
+		fmt.Printf("  %s  %10d  %s", f.SHA256[:12], f.Size, f.Path)
+		if f.Ref != "" {
+			fmt.Printf("  (未变化，见 %s)", f.Ref)
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,9 @@
+package cmd
+
+import (
+	"qs-tools/internal/cmd/export"
+)
+
+func init() {
+	RootCmd.AddCommand(export.Command())
+}
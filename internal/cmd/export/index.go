@@ -0,0 +1,126 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"qs-tools/internal/component"
+	"qs-tools/internal/config"
+	"qs-tools/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// Command 返回导出命令
+func Command() *cobra.Command {
+	return ExportCmd
+}
+
+var (
+	// components 要导出的组件，为空时导出所有有本地配置的组件
+	components []string
+	// output 离线包路径
+	output string
+	// secretPolicy 命令行指定的密钥处理策略，为空时使用配置文件中的设置
+	secretPolicy string
+)
+
+// ExportCmd 表示 export 命令
+var ExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "导出配置到离线包",
+	Long: `将组件配置导出为一个离线包文件（.qsb），用于无法连接远程服务器的主机，
+在目标主机上通过 "qs-tools import <file>" 恢复。
+
+离线包中每个组件的备份与上传到远程服务器的备份格式相同，都是全量备份，
+同样会扫描疑似密钥，设置了 QS_TOOLS_PASSPHRASE 时同样会加密。
+未指定 --components 时导出所有有本地配置的组件。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		selected, err := exportedComponents(components)
+		if err != nil {
+			return err
+		}
+		if len(selected) == 0 {
+			return fmt.Errorf("没有可以导出的组件")
+		}
+
+		path := output
+		if path == "" {
+			hostname, _ := os.Hostname()
+			path = fmt.Sprintf("qs-tools-%s-%s%s", hostname, time.Now().Format("20060102-150405"), utils.BundleExt)
+		}
+		return exportBundle(path, selected)
+	},
+}
+
+func init() {
+	ExportCmd.Flags().StringSliceVarP(&components, "components", "c", nil, "要导出的组件，以逗号分隔，默认导出所有有本地配置的组件")
+	ExportCmd.Flags().StringVarP(&output, "output", "o", "", "离线包路径，默认为当前目录下的 qs-tools-<主机名>-<时间>.qsb")
+	ExportCmd.Flags().StringVar(&secretPolicy, "secrets", "",
+		fmt.Sprintf("发现疑似密钥时的处理策略 (%s)，默认取消导出", strings.Join(utils.SecretPolicyNames(), "|")))
+	ExportCmd.RegisterFlagCompletionFunc("components", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return component.Names(component.OpBackup), cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// exportedComponents 返回要导出的组件
+func exportedComponents(names []string) ([]component.Component, error) {
+	if len(names) == 0 {
+		var selected []component.Component
+		for _, c := range component.All(component.OpBackup) {
+			if component.HasLocalConfig(c) {
+				selected = append(selected, c)
+			}
+		}
+		return selected, nil
+	}
+
+	var selected []component.Component
+	for _, name := range names {
+		c, ok := component.Get(name)
+		if !ok || !component.Supports(c, component.OpBackup) {
+			return nil, fmt.Errorf("不支持的组件: %s", name)
+		}
+		if err := component.CheckPlatform(c); err != nil {
+			return nil, err
+		}
+		selected = append(selected, c)
+	}
+	return selected, nil
+}
+
+// exportBundle 将组件配置依次备份到离线包中，任一组件失败时不生成离线包
+func exportBundle(path string, selected []component.Component) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	b := utils.NewBundle(path)
+
+	for _, c := range selected {
+		policy := secretPolicy
+		if policy == "" {
+			policy = cfg.SecretPolicy(c.Name())
+		}
+		secrets, err := utils.ParseSecretPolicy(policy)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("导出 %s 配置...\n", c.Description())
+		if _, err := component.Backup(c, utils.BackupOptions{Full: true, Secrets: secrets, Store: b}); err != nil {
+			return fmt.Errorf("导出 %s 失败: %v", c.Description(), err)
+		}
+		fmt.Println()
+	}
+
+	if err := b.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 已导出 %s 到离线包: %s\n", strings.Join(b.Components(), ", "), path)
+	return nil
+}
@@ -3,6 +3,8 @@ package install
 import (
 	"fmt"
 
+	"qs-tools/internal/component"
+
 	"github.com/spf13/cobra"
 )
 
@@ -14,44 +16,56 @@ func Command() *cobra.Command {
 var Cmd = &cobra.Command{
 	Use:   "install [component]",
 	Short: "安装常用工具和软件",
-	Long: `安装常用的工具和软件。
+	Long: fmt.Sprintf(`安装常用的工具和软件。
 目前支持的组件：
-  - fish: 安装 Fish Shell
-  - asdf: 安装 asdf 版本管理器
-  - yazi: 安装 Yazi 文件管理器
-  - scoop: 安装 Scoop 包管理器 (Windows)
-  - nvim: 安装 Neovim 编辑器
+%s
 
 支持的系统：
   - Ubuntu 及衍生版
   - Debian 及衍生版
   - Kylin (银河麒麟)
-  - Windows`,
+  - Windows`, component.HelpList(component.OpInstall, "安装 %s")),
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
 			fmt.Println("请指定要安装的组件")
 			return
 		}
 
-		var err error
-		switch args[0] {
-		case "fish":
-			installFish()
-		case "asdf":
-			installAsdf()
-		case "yazi":
-			err = installYazi()
-		case "scoop":
-			installScoop()
-		case "nvim":
-			err = installNvim()
-		default:
-			fmt.Printf("不支持的组件: %s\n", args[0])
-			return
-		}
-
-		if err != nil {
-			fmt.Printf("安装失败: %v\n", err)
-		}
+		fmt.Printf("不支持的组件: %s\n", args[0])
 	},
 }
+
+func init() {
+	// 为每个组件生成子命令
+	for _, c := range component.All(component.OpInstall) {
+		Cmd.AddCommand(newComponentCmd(c))
+	}
+}
+
+// newComponentCmd 生成安装组件的子命令
+func newComponentCmd(c component.Component) *cobra.Command {
+	return &cobra.Command{
+		Use:   c.Name(),
+		Short: fmt.Sprintf("安装 %s", c.Description()),
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return installComponent(c)
+		},
+	}
+}
+
+func installComponent(c component.Component) error {
+	if err := component.CheckPlatform(c); err != nil {
+		return err
+	}
+
+	if err := component.Install(c); err != nil {
+		return fmt.Errorf("安装失败: %v", err)
+	}
+
+	// 安装完成后检查是否可用
+	if err := c.Verify(); err != nil {
+		fmt.Printf("\n⚠️ %s 安装后检查未通过: %v\n", c.Description(), err)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+package cmd
+
+import (
+	"qs-tools/internal/cmd/nvim"
+)
+
+func init() {
+	RootCmd.AddCommand(nvim.Command())
+}
@@ -0,0 +1,161 @@
+package nvim
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"qs-tools/internal/component"
+
+	"github.com/spf13/cobra"
+)
+
+// Command 返回 Neovim 配置管理命令
+func Command() *cobra.Command {
+	return NvimCmd
+}
+
+// localOnly 只列出本地的配置，不连接远程服务器
+var localOnly bool
+
+// NvimCmd 表示 nvim 命令
+var NvimCmd = &cobra.Command{
+	Use:   "nvim",
+	Short: "管理多份 Neovim 配置",
+	Long: `Neovim 可以通过环境变量 NVIM_APPNAME 同时使用多份配置，
+每份配置位于配置目录下的同名目录（如 ~/.config/work），未设置时为 nvim。
+
+每份配置单独备份和恢复，如 "qs-tools backup nvim work"、"qs-tools apply nvim work"，
+在远程服务器上保存为组件 nvim@work，未指定配置时使用默认的配置。`,
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出本地和远程服务器上的 Neovim 配置",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listProfiles()
+	},
+}
+
+var switchCmd = &cobra.Command{
+	Use:   "switch <profile>",
+	Short: "切换默认的 Neovim 配置",
+	Long: `切换默认的 Neovim 配置，之后未指定配置的 "qs-tools backup nvim"、
+"qs-tools apply nvim" 都使用该配置。同时在用户环境中设置 NVIM_APPNAME
+（Windows 为用户环境变量，其他系统为 fish 的全局变量），切换为 nvim 时删除该变量。`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		c, err := nvimComponent()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		profiles, _ := component.Profiles(c)
+		return profiles, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := nvimComponent()
+		if err != nil {
+			return err
+		}
+		if err := component.SwitchProfile(c, args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("✅ 默认的 Neovim 配置已切换为 %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	listCmd.Flags().BoolVar(&localOnly, "local", false, "只列出本地的配置，不连接远程服务器")
+	NvimCmd.AddCommand(listCmd, switchCmd)
+}
+
+// nvimComponent 返回默认配置的 Neovim 组件
+func nvimComponent() (component.Component, error) {
+	c, ok := component.Get("nvim")
+	if !ok {
+		return nil, fmt.Errorf("不支持的组件: nvim")
+	}
+	return c, nil
+}
+
+// listProfiles 列出本地和远程服务器上的配置，默认的配置以 * 标出
+func listProfiles() error {
+	c, err := nvimComponent()
+	if err != nil {
+		return err
+	}
+	current, err := component.DefaultProfile(c)
+	if err != nil {
+		return err
+	}
+
+	local, err := component.Profiles(c)
+	if err != nil {
+		return err
+	}
+	var remote []string
+	var remoteErr error
+	if !localOnly {
+		remote, remoteErr = component.RemoteProfiles(c)
+	}
+
+	inLocal := make(map[string]bool)
+	inRemote := make(map[string]bool)
+	names := []string{current}
+	for _, p := range local {
+		inLocal[p] = true
+		names = append(names, p)
+	}
+	for _, p := range remote {
+		inRemote[p] = true
+		names = append(names, p)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if localOnly {
+		fmt.Fprintln(w, "\t配置\t本地")
+	} else {
+		fmt.Fprintln(w, "\t配置\t本地\t远程")
+	}
+	for i, name := range names {
+		if i > 0 && name == names[i-1] {
+			continue
+		}
+		marker := ""
+		if name == current {
+			marker = "*"
+		}
+		if localOnly {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", marker, name, mark(inLocal[name]))
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, name, mark(inLocal[name]), remoteMark(inRemote[name], remoteErr))
+		}
+	}
+	w.Flush()
+
+	if remoteErr != nil {
+		fmt.Printf("⚠️ 读取远程服务器上的配置失败: %v\n", remoteErr)
+	}
+	return nil
+}
+
+func mark(ok bool) string {
+	if ok {
+		return "✓"
+	}
+	return "✗"
+}
+
+func remoteMark(ok bool, err error) string {
+	if err != nil {
+		return "?"
+	}
+	return mark(ok)
+}
@@ -0,0 +1,9 @@
+package cmd
+
+import (
+	"qs-tools/internal/cmd/rollback"
+)
+
+func init() {
+	RootCmd.AddCommand(rollback.Command())
+}
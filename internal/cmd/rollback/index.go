@@ -0,0 +1,84 @@
+package rollback
+
+import (
+	"fmt"
+
+	"qs-tools/internal/component"
+
+	"github.com/spf13/cobra"
+)
+
+// Command 返回回滚命令
+func Command() *cobra.Command {
+	return RollbackCmd
+}
+
+// listSnapshots 只列出快照
+var listSnapshots bool
+
+// RollbackCmd 表示 rollback 命令
+var RollbackCmd = &cobra.Command{
+	Use:   "rollback <component> [snapshot]",
+	Short: "回滚到恢复前的本地配置",
+	Long: `每次执行 apply 前都会在状态目录（默认为 ~/.local/state/qs-tools）的 snapshots 中保存本地配置的快照，
+每个组件保留最近的几个快照。恢复的配置有问题时可以通过该命令回滚。
+未指定快照时回滚到最新的快照，使用 --list 查看可用的快照。`,
+	Args: cobra.RangeArgs(1, 2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return component.Names(component.OpApply), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, ok := component.Get(args[0])
+		if !ok {
+			return fmt.Errorf("不支持的组件: %s", args[0])
+		}
+
+		if listSnapshots {
+			return printSnapshots(c)
+		}
+
+		id := ""
+		if len(args) > 1 {
+			id = args[1]
+		}
+		return rollbackComponent(c, id)
+	},
+}
+
+func init() {
+	RollbackCmd.Flags().BoolVar(&listSnapshots, "list", false, "列出组件的本地快照")
+}
+
+func rollbackComponent(c component.Component, id string) error {
+	fmt.Printf("开始回滚 %s 配置...\n", c.Description())
+
+	snapshot, err := component.Rollback(c, id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n✅ %s 配置已回滚到快照 %s（%s）\n", c.Description(), snapshot.ID,
+		snapshot.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+func printSnapshots(c component.Component) error {
+	snapshots, err := component.Snapshots(c)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) == 0 {
+		fmt.Printf("%s 没有本地快照\n", c.Description())
+		return nil
+	}
+
+	fmt.Printf("%s 的本地快照：\n", c.Description())
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		s := snapshots[i]
+		fmt.Printf("  %s  %s  %d 个文件\n", s.ID, s.CreatedAt.Local().Format("2006-01-02 15:04:05"), s.Files)
+	}
+	return nil
+}
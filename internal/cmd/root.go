@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 
+	"qs-tools/internal/component"
+
 	"github.com/spf13/cobra"
 )
 
@@ -12,6 +14,21 @@ var RootCmd = &cobra.Command{
 	Short: "qs-tools - 一个实用的命令行工具集",
 	Long: `qs-tools 是一个集成了多种实用功能的命令行工具集。
 可以帮助你完成各种日常任务，提高工作效率。`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// 配置文件中声明的组件在执行命令前加载一次，--help 时不会执行到这里
+		warnings, err := component.LoadCustom()
+		if cmd.Name() == cobra.ShellCompRequestCmd || cmd.Name() == cobra.ShellCompNoDescRequestCmd {
+			// 补全的输出由 shell 解析，不输出警告
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, w := range warnings {
+			fmt.Printf("⚠️ %s\n", w)
+		}
+		return nil
+	},
 }
 
 func Execute() {
@@ -0,0 +1,9 @@
+package cmd
+
+import (
+	"qs-tools/internal/cmd/schedule"
+)
+
+func init() {
+	RootCmd.AddCommand(schedule.Command())
+}
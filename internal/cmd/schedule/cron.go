@@ -0,0 +1,149 @@
+package schedule
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"qs-tools/internal/config"
+	"qs-tools/internal/utils"
+)
+
+// cronMarker 标记 qs-tools 添加的 crontab 条目，用于替换和删除
+const cronMarker = "# " + unitName
+
+// cron 没有 systemd 时通过 crontab 执行定时备份
+type cron struct{}
+
+func (cron) name() string {
+	return "crontab"
+}
+
+// readCrontab 读取当前用户的 crontab，没有 crontab 时返回空
+func readCrontab() (string, error) {
+	if _, err := exec.LookPath("crontab"); err != nil {
+		return "", fmt.Errorf("未找到 systemd 用户实例或 crontab 命令，无法启用定时备份")
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("crontab", "-l")
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		// 用户还没有 crontab 时 crontab -l 返回错误并提示 no crontab for ...
+		if strings.Contains(stderr.String(), "no crontab") {
+			return "", nil
+		}
+		return "", fmt.Errorf("读取 crontab 失败: %v", err)
+	}
+	return string(output), nil
+}
+
+// writeCrontab 替换当前用户的 crontab
+func writeCrontab(content string) error {
+	cmd := exec.Command("crontab", "-")
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("写入 crontab 失败: %v", err)
+	}
+	return nil
+}
+
+// removeEntries 删除 crontab 中带有标记的条目，返回剩余的内容和删除的条目
+func removeEntries(crontab string) (string, []string) {
+	var kept, removed []string
+	for _, line := range strings.Split(strings.TrimRight(crontab, "\n"), "\n") {
+		if strings.HasSuffix(strings.TrimSpace(line), cronMarker) {
+			removed = append(removed, line)
+			continue
+		}
+		kept = append(kept, line)
+	}
+	content := strings.Join(kept, "\n")
+	if strings.TrimSpace(content) == "" {
+		return "", removed
+	}
+	return content + "\n", removed
+}
+
+// cronEntry 生成定时备份的 crontab 条目，输出追加到状态目录下的 backup.log
+func cronEntry(every string, commands [][]string) (string, error) {
+	stateDir, err := utils.StateDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return "", fmt.Errorf("创建状态目录失败: %v", err)
+	}
+	logPath := filepath.Join(stateDir, "backup.log")
+
+	var lines []string
+	for _, args := range commands {
+		lines = append(lines, commandLine(args)+" >> "+shellQuote(logPath)+" 2>&1")
+	}
+	job := strings.Join(lines, "; ")
+	if path := os.Getenv(config.ConfigFileEnv); path != "" {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		job = "export " + config.ConfigFileEnv + "=" + shellQuote(path) + "; " + job
+	}
+	// cron 会把命令中未转义的 % 换成换行，路径中的 % 需要转义
+	job = strings.ReplaceAll(job, "%", `\%`)
+	return fmt.Sprintf("%s %s %s", intervals[every], job, cronMarker), nil
+}
+
+func (cron) enable(every string, commands [][]string) error {
+	crontab, err := readCrontab()
+	if err != nil {
+		return err
+	}
+	entry, err := cronEntry(every, commands)
+	if err != nil {
+		return err
+	}
+
+	content, _ := removeEntries(crontab)
+	if err := writeCrontab(content + entry + "\n"); err != nil {
+		return err
+	}
+	fmt.Printf("已添加 crontab 条目: %s\n", entry)
+	return nil
+}
+
+func (cron) status() error {
+	crontab, err := readCrontab()
+	if err != nil {
+		return err
+	}
+	_, entries := removeEntries(crontab)
+	if len(entries) == 0 {
+		fmt.Println("未启用定时备份")
+		return nil
+	}
+
+	fmt.Println("方式: crontab")
+	for _, entry := range entries {
+		fmt.Printf("条目: %s\n", entry)
+	}
+	if stateDir, err := utils.StateDir(); err == nil {
+		fmt.Printf("\n备份日志: %s\n", filepath.Join(stateDir, "backup.log"))
+	}
+	return nil
+}
+
+func (cron) disable() error {
+	crontab, err := readCrontab()
+	if err != nil {
+		return err
+	}
+	content, removed := removeEntries(crontab)
+	if len(removed) == 0 {
+		fmt.Println("未启用定时备份")
+		return nil
+	}
+	return writeCrontab(content)
+}
@@ -0,0 +1,172 @@
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"qs-tools/internal/component"
+	"qs-tools/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// Command 返回定时备份命令
+func Command() *cobra.Command {
+	return ScheduleCmd
+}
+
+// every 备份周期
+var every string
+
+// intervals 支持的备份周期，值为 cron 表达式，systemd 直接使用周期名称作为 OnCalendar
+var intervals = map[string]string{
+	"hourly": "0 * * * *",
+	"daily":  "0 0 * * *",
+	"weekly": "0 0 * * 1",
+}
+
+// ScheduleCmd 表示 schedule 命令
+var ScheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "管理定时备份",
+	Long: `定时执行备份。优先安装为 systemd 用户定时器（qs-tools-backup.timer），
+系统没有 systemd 用户实例时（如部分银河麒麟系统）改为添加 crontab 条目。`,
+}
+
+var enableCmd = &cobra.Command{
+	Use:   "enable [component...]",
+	Short: "启用定时备份",
+	Long: `启用定时备份，未指定组件时备份所有有本地配置的组件（qs-tools backup --all）。
+重复执行会替换已有的定时备份。`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return component.Names(component.OpBackup), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, ok := intervals[every]; !ok {
+			return fmt.Errorf("不支持的备份周期: %s（可选 hourly、daily、weekly）", every)
+		}
+		for _, name := range args {
+			if _, ok := component.Get(name); !ok {
+				return fmt.Errorf("不支持的组件: %s", name)
+			}
+		}
+		if err := checkPlatform(); err != nil {
+			return err
+		}
+
+		commands, err := backupCommands(args)
+		if err != nil {
+			return err
+		}
+
+		s := newScheduler()
+		if err := s.enable(every, commands); err != nil {
+			return err
+		}
+
+		fmt.Printf("\n✅ 已通过 %s 启用定时备份（%s）\n", s.name(), every)
+		if os.Getenv(config.BackupPassphraseEnv) != "" {
+			fmt.Printf("⚠️ 定时备份不会继承当前终端的环境变量 %s，加密备份需要另外为定时任务提供口令\n", config.BackupPassphraseEnv)
+		}
+		return nil
+	},
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "查看定时备份状态",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkPlatform(); err != nil {
+			return err
+		}
+		return newScheduler().status()
+	},
+}
+
+var disableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "停用定时备份",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkPlatform(); err != nil {
+			return err
+		}
+		s := newScheduler()
+		if err := s.disable(); err != nil {
+			return err
+		}
+		fmt.Println("\n✅ 已停用定时备份")
+		return nil
+	},
+}
+
+func init() {
+	enableCmd.Flags().StringVar(&every, "every", "daily", "备份周期 (hourly|daily|weekly)")
+	ScheduleCmd.AddCommand(enableCmd, statusCmd, disableCmd)
+}
+
+// scheduler 定时任务的安装方式
+type scheduler interface {
+	name() string
+	enable(every string, commands [][]string) error
+	status() error
+	disable() error
+}
+
+// newScheduler 有 systemd 用户实例时使用 systemd 定时器，否则使用 crontab
+func newScheduler() scheduler {
+	if systemdAvailable() {
+		return systemd{}
+	}
+	return cron{}
+}
+
+func checkPlatform() error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("定时备份仅支持 Linux 系统")
+	}
+	return nil
+}
+
+// backupCommands 返回定时执行的备份命令，使用当前 qs-tools 可执行文件的绝对路径
+func backupCommands(components []string) ([][]string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("获取 qs-tools 路径失败: %v", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return nil, fmt.Errorf("获取 qs-tools 路径失败: %v", err)
+	}
+
+	if len(components) == 0 {
+		return [][]string{{exe, "backup", "--all"}}, nil
+	}
+	var commands [][]string
+	for _, name := range components {
+		// 组件的其他配置（如 nvim@work）通过参数指定配置
+		args := append([]string{exe, "backup"}, strings.SplitN(name, component.ProfileSeparator, 2)...)
+		commands = append(commands, args)
+	}
+	return commands, nil
+}
+
+// shellQuote 为 shell 命令中的参数加上引号
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`!*?[]{}()<>|&;#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// commandLine 将命令拼接为 shell 命令行
+func commandLine(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, a := range args {
+		quoted = append(quoted, shellQuote(a))
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,176 @@
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"qs-tools/internal/config"
+	"qs-tools/internal/xdg"
+)
+
+// unitName systemd 用户单元的名称
+const unitName = "qs-tools-backup"
+
+// systemd 通过 systemd 用户定时器执行定时备份
+type systemd struct{}
+
+// systemdAvailable 判断当前用户是否有可用的 systemd 用户实例
+func systemdAvailable() bool {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return false
+	}
+	return exec.Command("systemctl", "--user", "show-environment").Run() == nil
+}
+
+func (systemd) name() string {
+	return "systemd 用户定时器"
+}
+
+// unitDir 返回 systemd 用户单元目录 $XDG_CONFIG_HOME/systemd/user，默认为 ~/.config/systemd/user
+func unitDir() (string, error) {
+	configHome, err := xdg.ConfigHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configHome, "systemd", "user"), nil
+}
+
+// serviceUnit 生成执行备份的 service 单元
+func serviceUnit(commands [][]string) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=qs-tools 定时备份\n")
+	b.WriteString("Wants=network-online.target\n")
+	b.WriteString("After=network-online.target\n\n")
+	b.WriteString("[Service]\n")
+	b.WriteString("Type=oneshot\n")
+	if path := os.Getenv(config.ConfigFileEnv); path != "" {
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		fmt.Fprintf(&b, "Environment=%s\n", escapeSpecifiers(commandLine([]string{config.ConfigFileEnv + "=" + path})))
+	}
+	for _, args := range commands {
+		// 备份多个组件时，一个组件备份失败不影响后续组件
+		prefix := ""
+		if len(commands) > 1 {
+			prefix = "-"
+		}
+		fmt.Fprintf(&b, "ExecStart=%s%s\n", prefix, escapeExec(commandLine(args)))
+	}
+	return b.String()
+}
+
+// escapeSpecifiers 转义单元文件中的 %，systemd 会把 % 开头的内容当作说明符展开
+func escapeSpecifiers(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
+
+// escapeExec 转义 ExecStart 中的说明符和 $，systemd 会把 $ 开头的内容当作环境变量展开。
+// Environment 不展开环境变量，只需要转义说明符
+func escapeExec(s string) string {
+	return strings.ReplaceAll(escapeSpecifiers(s), "$", "$$")
+}
+
+// timerUnit 生成按周期触发备份的 timer 单元，错过的备份会在开机后补上
+func timerUnit(every string) string {
+	return fmt.Sprintf(`[Unit]
+Description=qs-tools 定时备份（%s）
+
+[Timer]
+OnCalendar=%s
+Persistent=true
+RandomizedDelaySec=5min
+
+[Install]
+WantedBy=timers.target
+`, every, every)
+}
+
+func (systemd) enable(every string, commands [][]string) error {
+	dir, err := unitDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建 systemd 单元目录失败: %v", err)
+	}
+
+	units := map[string]string{
+		unitName + ".service": serviceUnit(commands),
+		unitName + ".timer":   timerUnit(every),
+	}
+	for name, content := range units {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("写入 %s 失败: %v", name, err)
+		}
+		fmt.Printf("已写入: %s\n", path)
+	}
+
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl("enable", "--now", unitName+".timer")
+}
+
+func (systemd) status() error {
+	dir, err := unitDir()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dir, unitName+".timer")); os.IsNotExist(err) {
+		fmt.Println("未启用定时备份")
+		return nil
+	}
+
+	state, _ := exec.Command("systemctl", "--user", "is-enabled", unitName+".timer").Output()
+	fmt.Printf("方式: systemd 用户定时器 (%s.timer)\n", unitName)
+	fmt.Printf("状态: %s\n\n", strings.TrimSpace(string(state)))
+
+	cmd := exec.Command("systemctl", "--user", "list-timers", unitName+".timer", "--all", "--no-pager")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("查看定时器失败: %v", err)
+	}
+
+	fmt.Printf("\n可以通过 journalctl --user -u %s.service 查看备份日志\n", unitName)
+	return nil
+}
+
+func (systemd) disable() error {
+	dir, err := unitDir()
+	if err != nil {
+		return err
+	}
+	timer := filepath.Join(dir, unitName+".timer")
+	if _, err := os.Stat(timer); os.IsNotExist(err) {
+		fmt.Println("未启用定时备份")
+		return nil
+	}
+
+	if err := systemctl("disable", "--now", unitName+".timer"); err != nil {
+		return err
+	}
+	for _, name := range []string{unitName + ".timer", unitName + ".service"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除 %s 失败: %v", name, err)
+		}
+	}
+	return systemctl("daemon-reload")
+}
+
+// systemctl 执行 systemctl --user 命令
+func systemctl(args ...string) error {
+	cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("执行 systemctl --user %s 失败: %v", strings.Join(args, " "), err)
+	}
+	return nil
+}
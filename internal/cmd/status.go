@@ -0,0 +1,9 @@
+package cmd
+
+import (
+	"qs-tools/internal/cmd/status"
+)
+
+func init() {
+	RootCmd.AddCommand(status.Command())
+}
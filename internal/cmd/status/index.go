@@ -0,0 +1,112 @@
+package status
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"qs-tools/internal/component"
+	"qs-tools/internal/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// Command 返回状态命令
+func Command() *cobra.Command {
+	return StatusCmd
+}
+
+// StatusCmd 表示 status 命令
+var StatusCmd = &cobra.Command{
+	Use:   "status [component...]",
+	Short: "查看组件的安装和备份状态",
+	Long: `列出各组件的安装情况、本地配置是否存在、远程最新备份的时间和主机，
+以及本地配置与最新备份的差异：
+  - synced: 与最新备份相同
+  - ahead: 本地有未备份的修改
+  - behind: 远程有更新的备份
+  - modified: 本地和远程都有变化，或没有同步记录无法判断
+  - no-backup: 远程服务器上没有备份`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return component.Names(component.OpBackup), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		components := component.All(component.OpBackup)
+		if len(args) > 0 {
+			components = nil
+			for _, name := range args {
+				c, ok := component.Get(name)
+				if !ok {
+					return fmt.Errorf("不支持的组件: %s", name)
+				}
+				components = append(components, c)
+			}
+		}
+		printStatus(components)
+		return nil
+	},
+}
+
+func printStatus(components []component.Component) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "组件\t安装\t本地配置\t最近备份\t状态")
+
+	var errs []string
+	for _, c := range components {
+		s := component.GetStatus(c)
+		if !s.Supported {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t不支持当前系统\n", c.Name())
+			continue
+		}
+		if s.Err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", c.Name(), s.Err))
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.Name(), mark(s.Installed), configMark(s.HasConfig),
+			latestBackup(s.Latest), stateText(s))
+	}
+	w.Flush()
+
+	for _, e := range errs {
+		fmt.Printf("⚠️ %s\n", e)
+	}
+}
+
+func mark(ok bool) string {
+	if ok {
+		return "✓"
+	}
+	return "✗"
+}
+
+func configMark(hasConfig *bool) string {
+	if hasConfig == nil {
+		return "-"
+	}
+	return mark(*hasConfig)
+}
+
+func latestBackup(meta *utils.BackupMeta) string {
+	if meta == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%s)", meta.CreatedAt.Local().Format(time.DateTime), meta.Hostname)
+}
+
+func stateText(s component.Status) string {
+	switch {
+	case s.Err != nil && s.State == "":
+		return "未知"
+	case s.State == "":
+		return "-"
+	case s.State == utils.SyncAhead:
+		return "ahead（需要备份）"
+	case s.State == utils.SyncBehind:
+		return "behind（可以恢复）"
+	case s.State == utils.SyncModified:
+		return "modified（本地与备份不同）"
+	default:
+		return string(s.State)
+	}
+}
@@ -3,16 +3,16 @@ package cmd
 import (
 	"fmt"
 
+	"qs-tools/internal/version"
+
 	"github.com/spf13/cobra"
 )
 
-var Version = "v0.1.0"
-
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "显示程序版本",
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("QS工具集 %s\n", Version)
+		fmt.Printf("QS工具集 %s\n", version.Version)
 	},
 }
 
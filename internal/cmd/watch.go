@@ -0,0 +1,9 @@
+package cmd
+
+import (
+	"qs-tools/internal/cmd/watch"
+)
+
+func init() {
+	RootCmd.AddCommand(watch.Command())
+}
@@ -0,0 +1,86 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"qs-tools/internal/component"
+	"qs-tools/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+// Command 返回监视命令
+func Command() *cobra.Command {
+	return WatchCmd
+}
+
+// debounce 最后一次修改后等待多久再备份
+var debounce time.Duration
+
+// WatchCmd 表示 watch 命令
+var WatchCmd = &cobra.Command{
+	Use:   "watch [component...]",
+	Short: "监视配置变化并自动备份",
+	Long: `监视组件的配置目录，配置修改后自动备份发生变化的组件。
+连续的修改会在最后一次修改后等待一段时间（--debounce）再备份，
+无法连接远程服务器时会逐渐延长重试间隔，恢复连接后继续备份。
+未指定组件时监视所有有本地配置的组件，按 Ctrl+C 退出。`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return component.Names(component.OpBackup), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		components, err := watchedComponents(args)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return run(ctx, components, debounce)
+	},
+}
+
+func init() {
+	WatchCmd.Flags().DurationVar(&debounce, "debounce", config.WatchDebounce, "最后一次修改后等待多久再备份")
+}
+
+// watchedComponents 返回要监视的组件，只有配置来自本地文件的组件可以监视
+func watchedComponents(names []string) ([]component.Component, error) {
+	if len(names) == 0 {
+		var components []component.Component
+		for _, c := range component.All(component.OpBackup) {
+			if component.CheckPlatform(c) != nil {
+				continue
+			}
+			if paths, err := c.ConfigPaths(); err == nil && len(paths) > 0 {
+				components = append(components, c)
+			}
+		}
+		return components, nil
+	}
+
+	var components []component.Component
+	for _, name := range names {
+		c, ok := component.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("不支持的组件: %s", name)
+		}
+		if err := component.CheckPlatform(c); err != nil {
+			return nil, err
+		}
+		paths, err := c.ConfigPaths()
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("%s 的配置不是本地文件，无法监视", c.Description())
+		}
+		components = append(components, c)
+	}
+	return components, nil
+}
@@ -0,0 +1,207 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"qs-tools/internal/component"
+	"qs-tools/internal/config"
+	"qs-tools/internal/utils"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watcher 监视组件的配置路径，按组件合并连续的修改后触发备份
+type watcher struct {
+	fs *fsnotify.Watcher
+	// roots 配置路径到组件的映射
+	roots      map[string]component.Component
+	components map[string]component.Component
+	// pending 等待备份的组件及其最后一次修改的时间
+	pending map[string]time.Time
+	// debounce 最后一次修改后等待多久再备份
+	debounce time.Duration
+	// retryAt 无法连接远程服务器时，下一次尝试备份的时间
+	retryAt time.Time
+	backoff time.Duration
+}
+
+// run 监视组件的配置并自动备份，直到 ctx 被取消，连续的修改在最后一次修改 debounce 后备份
+func run(ctx context.Context, components []component.Component, debounce time.Duration) error {
+	if len(components) == 0 {
+		return fmt.Errorf("没有可以监视的组件")
+	}
+
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("创建文件监视失败: %v", err)
+	}
+	defer fw.Close()
+
+	w := &watcher{
+		fs:         fw,
+		roots:      make(map[string]component.Component),
+		components: make(map[string]component.Component),
+		pending:    make(map[string]time.Time),
+		debounce:   debounce,
+	}
+	for _, c := range components {
+		paths, err := c.ConfigPaths()
+		if err != nil {
+			return err
+		}
+		w.components[c.Name()] = c
+		for _, p := range paths {
+			w.roots[filepath.Clean(p)] = c
+			w.watchRoot(p)
+		}
+		fmt.Printf("监视 %s: %s\n", c.Description(), strings.Join(paths, ", "))
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Println("停止监视")
+			return nil
+		case event, ok := <-fw.Events:
+			if !ok {
+				return nil
+			}
+			w.handleEvent(event)
+		case err, ok := <-fw.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Printf("⚠️ 文件监视出错: %v\n", err)
+		case now := <-ticker.C:
+			w.flush(now)
+		}
+	}
+}
+
+// watchRoot 监视一个配置路径：目录递归监视其中的全部子目录，
+// 文件和尚不存在的路径监视所在的目录，以便发现编辑器替换文件或新建配置
+func (w *watcher) watchRoot(root string) {
+	info, err := os.Stat(root)
+	if err == nil && info.IsDir() {
+		w.watchTree(root)
+		return
+	}
+	if err := w.fs.Add(filepath.Dir(root)); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("⚠️ 无法监视 %s: %v\n", filepath.Dir(root), err)
+	}
+}
+
+// watchTree 递归监视目录及其子目录
+func (w *watcher) watchTree(dir string) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if err := w.fs.Add(path); err != nil {
+			fmt.Printf("⚠️ 无法监视 %s: %v\n", path, err)
+		}
+		return nil
+	})
+}
+
+// handleEvent 记录发生变化的组件，新建的目录加入监视
+func (w *watcher) handleEvent(event fsnotify.Event) {
+	if event.Op == fsnotify.Chmod {
+		return
+	}
+
+	c := w.componentFor(event.Name)
+	if c == nil {
+		return
+	}
+
+	if event.Op.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			w.watchTree(event.Name)
+		}
+	}
+
+	if _, ok := w.pending[c.Name()]; !ok {
+		fmt.Printf("%s 配置有变化: %s\n", c.Description(), event.Name)
+	}
+	w.pending[c.Name()] = time.Now()
+}
+
+// componentFor 返回路径所属的组件，不属于任何配置路径时返回 nil
+func (w *watcher) componentFor(path string) component.Component {
+	var best string
+	for root := range w.roots {
+		if path != root && !strings.HasPrefix(path, root+string(filepath.Separator)) {
+			continue
+		}
+		if len(root) > len(best) {
+			best = root
+		}
+	}
+	if best == "" {
+		return nil
+	}
+	return w.roots[best]
+}
+
+// flush 备份修改后已经等待足够时间的组件
+func (w *watcher) flush(now time.Time) {
+	if now.Before(w.retryAt) {
+		return
+	}
+
+	for name, last := range w.pending {
+		if now.Sub(last) < w.debounce {
+			continue
+		}
+
+		err := w.backup(w.components[name])
+		if errors.Is(err, utils.ErrRemoteUnavailable) {
+			// 保留等待备份的组件，稍后重试
+			w.backoff = min(max(w.backoff*2, config.WatchRetryInterval), config.WatchMaxRetryInterval)
+			w.retryAt = now.Add(w.backoff)
+			fmt.Printf("⚠️ %v，%s 后重试\n", err, w.backoff)
+			return
+		}
+
+		w.backoff = 0
+		delete(w.pending, name)
+		if err != nil {
+			fmt.Printf("❌ 备份 %s 失败: %v\n", w.components[name].Description(), err)
+		}
+	}
+}
+
+// backup 备份组件，密钥处理策略使用配置文件中的设置
+func (w *watcher) backup(c component.Component) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	policy, err := utils.ParseSecretPolicy(cfg.SecretPolicy(c.Name()))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n开始备份 %s 配置...\n", c.Description())
+	result, err := component.Backup(c, utils.BackupOptions{Secrets: policy})
+	if err != nil {
+		return err
+	}
+	if result.Unchanged {
+		fmt.Printf("✅ %s 配置与最新的备份相同，跳过上传\n", c.Description())
+		return nil
+	}
+	fmt.Printf("✅ %s 配置备份成功，版本: %s\n", c.Description(), result.Meta.ID)
+	return nil
+}
@@ -0,0 +1,204 @@
+package component
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"qs-tools/internal/manifest"
+	"qs-tools/internal/utils"
+	"qs-tools/internal/xdg"
+)
+
+func init() {
+	Register(asdf{})
+}
+
+// asdf asdf 版本管理器组件
+type asdf struct{}
+
+func (asdf) Name() string        { return "asdf" }
+func (asdf) Description() string { return "asdf 版本管理器" }
+func (asdf) Platforms() []string { return []string{"linux"} }
+
+func (a asdf) ConfigPaths() ([]string, error) {
+	targets, err := a.targets()
+	if err != nil {
+		return nil, err
+	}
+	return targets.Paths(), nil
+}
+
+// targets 备份全局工具版本和 asdf 配置文件，插件和已安装的版本不备份。
+// 配置文件与 asdf 一致，设置了 ASDF_CONFIG_FILE 时使用该文件
+func (asdf) targets() (manifest.Targets, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("获取用户主目录失败: %v", err)
+	}
+	// ASDF_CONFIG_FILE 是文件路径，与目录一样只接受绝对路径
+	configFile := os.Getenv("ASDF_CONFIG_FILE")
+	if !filepath.IsAbs(configFile) {
+		configFile = filepath.Join(homeDir, ".asdfrc")
+	}
+	return manifest.Targets{
+		{Name: ".tool-versions", Path: filepath.Join(homeDir, ".tool-versions")},
+		{Name: ".asdfrc", Path: configFile},
+	}, nil
+}
+
+// asdfDataDir 返回 asdf 的安装目录，设置了 ASDF_DATA_DIR 时使用该目录，默认为 ~/.asdf
+func asdfDataDir() (string, error) {
+	if dir := xdg.Env("ASDF_DATA_DIR"); dir != "" {
+		return dir, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户主目录失败: %v", err)
+	}
+	return filepath.Join(homeDir, ".asdf"), nil
+}
+
+func (a asdf) Backup(opts utils.BackupOptions) (*utils.BackupResult, error) {
+	targets, err := a.targets()
+	if err != nil {
+		return nil, err
+	}
+	return backupTargets(a, targets, utils.ToolVersion("asdf", "--version"), opts)
+}
+
+func (a asdf) Apply(opts utils.ApplyOptions) error {
+	targets, err := a.targets()
+	if err != nil {
+		return err
+	}
+	return applyTargets(a, targets, opts)
+}
+
+func (asdf) Verify() error {
+	asdfDir, err := asdfDataDir()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(asdfDir); err == nil {
+		return nil
+	}
+	return verifyCommand("asdf")
+}
+
+// Install 安装 asdf 版本管理器
+func (asdf) Install() error {
+	// 检查是否为支持的系统
+	if !isDebianBased() {
+		return fmt.Errorf("当前系统不是基于 Debian 的系统（如 Ubuntu、Debian、Kylin 等）")
+	}
+
+	fmt.Println("开始安装 asdf 版本管理器...")
+
+	// 安装依赖
+	fmt.Println("\n1. 安装必要的依赖...")
+	deps := []string{
+		"curl",
+		"git",
+		"make",
+		"unzip",
+	}
+
+	installDepsCmd := exec.Command("sudo", append([]string{"apt", "install", "-y"}, deps...)...)
+	installDepsCmd.Stdout = os.Stdout
+	installDepsCmd.Stderr = os.Stderr
+	if err := installDepsCmd.Run(); err != nil {
+		return fmt.Errorf("安装依赖失败: %v", err)
+	}
+
+	// 获取用户主目录
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("获取用户主目录失败: %v", err)
+	}
+
+	// 检查是否已安装
+	asdfDir, err := asdfDataDir()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(asdfDir); err == nil {
+		fmt.Printf("\nasdf 已经安装。如需重新安装，请先删除 %s 目录\n", asdfDir)
+		return nil
+	}
+
+	// 克隆 asdf 仓库
+	fmt.Println("\n2. 克隆 asdf 仓库...")
+	cloneCmd := exec.Command("git", "clone", "https://github.com/asdf-vm/asdf.git", asdfDir)
+	cloneCmd.Stdout = os.Stdout
+	cloneCmd.Stderr = os.Stderr
+	if err := cloneCmd.Run(); err != nil {
+		return fmt.Errorf("克隆 asdf 仓库失败: %v", err)
+	}
+
+	// 检查当前 Shell
+	currentShell := os.Getenv("SHELL")
+	if currentShell == "" {
+		currentShell = "/bin/bash" // 默认使用 bash
+	}
+
+	// 配置 Shell 集成
+	fmt.Println("\n3. 配置 Shell 集成...")
+	shellConfigFile := ""
+	shellInitCmd := ""
+
+	switch {
+	case strings.Contains(currentShell, "fish"):
+		fishConfigDir, err := utils.ComponentConfigDir("fish")
+		if err != nil {
+			return err
+		}
+		shellConfigFile = filepath.Join(fishConfigDir, "config.fish")
+		shellInitCmd = "source " + filepath.Join(asdfDir, "asdf.fish")
+	case strings.Contains(currentShell, "zsh"):
+		shellConfigFile = filepath.Join(homeDir, ".zshrc")
+		shellInitCmd = ". " + filepath.Join(asdfDir, "asdf.sh")
+	default: // bash
+		shellConfigFile = filepath.Join(homeDir, ".bashrc")
+		shellInitCmd = ". " + filepath.Join(asdfDir, "asdf.sh")
+	}
+
+	// 确保配置目录存在
+	if err := os.MkdirAll(filepath.Dir(shellConfigFile), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+
+	// 添加初始化命令到 Shell 配置
+	f, err := os.OpenFile(shellConfigFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("打开 Shell 配置文件失败: %v", err)
+	}
+	defer f.Close()
+
+	// 检查是否已经配置
+	content, err := os.ReadFile(shellConfigFile)
+	if err == nil && !strings.Contains(string(content), "asdf.") {
+		if _, err := f.WriteString("\n# asdf 版本管理器\n" + shellInitCmd + "\n"); err != nil {
+			return fmt.Errorf("写入 Shell 配置失败: %v", err)
+		}
+	}
+
+	fmt.Println("\n✅ asdf 安装成功！")
+	fmt.Println("\n使用说明：")
+	fmt.Println("1. 重新打开终端或执行以下命令使配置生效：")
+	fmt.Printf("   source %s\n", shellConfigFile)
+	fmt.Println("\n2. 常用命令：")
+	fmt.Println("   - 查看可用插件：asdf plugin list all")
+	fmt.Println("   - 安装插件：asdf plugin add <name>")
+	fmt.Println("   - 安装特定版本：asdf install <name> <version>")
+	fmt.Println("   - 设置全局版本：asdf global <name> <version>")
+	fmt.Println("   - 设置本地版本：asdf local <name> <version>")
+	fmt.Println("   - 查看当前版本：asdf current")
+	fmt.Println("\n3. 示例 - 安装 Node.js：")
+	fmt.Println("   asdf plugin add nodejs")
+	fmt.Println("   asdf install nodejs latest")
+	fmt.Println("   asdf global nodejs latest")
+	return nil
+}
@@ -0,0 +1,239 @@
+package component
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"qs-tools/internal/config"
+	"qs-tools/internal/utils"
+)
+
+// ErrNotSupported 组件不支持该操作
+var ErrNotSupported = errors.New("不支持该操作")
+
+// Component 可以安装、备份和恢复的组件。
+// 新增工具时只需实现该接口并在 init 中调用 Register，
+// install、backup、apply 的子命令、帮助信息和补全都会自动生成
+type Component interface {
+	// Name 组件名称，同时作为子命令名
+	Name() string
+	// Description 组件的显示名称，用于帮助信息和提示
+	Description() string
+	// Platforms 支持的操作系统（runtime.GOOS 的取值），为空表示支持所有系统
+	Platforms() []string
+	// ConfigPaths 组件配置在当前系统上的路径
+	ConfigPaths() ([]string, error)
+	// Install 安装组件
+	Install() error
+	// Backup 备份组件配置并上传到远程服务器
+	Backup(opts utils.BackupOptions) (*utils.BackupResult, error)
+	// Apply 从远程服务器恢复组件配置
+	Apply(opts utils.ApplyOptions) error
+	// Verify 检查组件是否已正确安装
+	Verify() error
+}
+
+// Operation 组件支持的操作
+type Operation string
+
+const (
+	// OpInstall 安装
+	OpInstall Operation = "install"
+	// OpBackup 备份
+	OpBackup Operation = "backup"
+	// OpApply 恢复
+	OpApply Operation = "apply"
+)
+
+// operationLimiter 只支持部分操作的组件实现该接口，未实现时视为支持所有操作
+type operationLimiter interface {
+	Supports(op Operation) bool
+}
+
+// ProfileSeparator 组件名称与配置名称之间的分隔符，如 nvim@work 表示 Neovim 的 work 配置
+const ProfileSeparator = "@"
+
+// profiler 同一工具可以有多份配置的组件实现该接口，
+// 每份配置作为名称为 <组件>@<配置> 的组件单独备份和恢复
+type profiler interface {
+	// Profile 返回组件当前使用的配置名称
+	Profile() string
+	// Profiles 返回本地已有的配置名称
+	Profiles() ([]string, error)
+	// WithProfile 返回使用指定配置的组件，name 为空时使用默认的配置
+	WithProfile(name string) (Component, error)
+	// DefaultProfile 返回默认的配置名称
+	DefaultProfile() (string, error)
+	// SwitchProfile 将默认的配置切换为 name
+	SwitchProfile(name string) error
+}
+
+var (
+	registry   = make(map[string]Component)
+	customOnce sync.Once
+	// customWarnings 加载配置文件中的组件时跳过的组件和有误的定义
+	customWarnings []string
+	customErr      error
+)
+
+// Register 注册组件，组件名称重复时 panic
+func Register(c Component) {
+	if _, ok := registry[c.Name()]; ok {
+		panic(fmt.Sprintf("组件重复注册: %s", c.Name()))
+	}
+	registry[c.Name()] = c
+}
+
+// LoadCustom 注册配置文件中声明的组件，只在第一次调用时读取配置文件。
+// 由根命令在执行命令前调用，构造命令时 All、Names 和 Get 只包含内置组件。
+// 返回与内置组件重名或定义有误而跳过的组件的警告，以及读取配置文件的错误
+func LoadCustom() ([]string, error) {
+	customOnce.Do(func() {
+		customWarnings, customErr = loadCustom()
+	})
+	return customWarnings, customErr
+}
+
+// loadCustom 读取配置文件并注册其中声明的组件
+func loadCustom() ([]string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	names := make([]string, 0, len(cfg.Components))
+	for name := range cfg.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cc := cfg.Components[name]
+		if name == "" || strings.ContainsAny(name, " \t/\\"+ProfileSeparator) {
+			warnings = append(warnings, fmt.Sprintf("忽略配置文件中的组件 %q: 名称无效", name))
+			continue
+		}
+		if err := checkHooks(cc); err != nil {
+			warnings = append(warnings, fmt.Sprintf("配置文件中组件 %s 的钩子有误: %v", name, err))
+		}
+		if _, ok := registry[name]; ok {
+			// 没有配置路径的是内置组件的设置
+			if len(cc.Paths) > 0 {
+				warnings = append(warnings, fmt.Sprintf("忽略配置文件中的组件 %s: 与内置组件重名", name))
+			}
+			continue
+		}
+		c := custom{name: name, cfg: cc}
+		if err := c.validate(); err != nil {
+			warnings = append(warnings, fmt.Sprintf("忽略配置文件中的组件 %s: %v", name, err))
+			continue
+		}
+		registry[name] = c
+	}
+	return warnings, nil
+}
+
+// Get 按名称查找组件，<组件>@<配置> 返回使用该配置的组件
+func Get(name string) (Component, bool) {
+	if c, ok := registry[name]; ok {
+		return c, true
+	}
+
+	base, profile, ok := strings.Cut(name, ProfileSeparator)
+	if !ok || profile == "" {
+		return nil, false
+	}
+	p, ok := registry[base].(profiler)
+	if !ok {
+		return nil, false
+	}
+	c, err := p.WithProfile(profile)
+	if err != nil {
+		return nil, false
+	}
+	return c, true
+}
+
+// All 返回支持指定操作的全部组件，按名称排序
+func All(op Operation) []Component {
+	var all []Component
+	for _, c := range registry {
+		if Supports(c, op) {
+			all = append(all, c)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name() < all[j].Name()
+	})
+	return all
+}
+
+// Names 返回支持指定操作的全部组件名称，用于命令补全
+func Names(op Operation) []string {
+	var names []string
+	for _, c := range All(op) {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+// Supports 判断组件是否支持指定操作
+func Supports(c Component, op Operation) bool {
+	if l, ok := c.(operationLimiter); ok {
+		return l.Supports(op)
+	}
+	return true
+}
+
+// CheckPlatform 检查组件是否支持当前操作系统
+func CheckPlatform(c Component) error {
+	platforms := c.Platforms()
+	if len(platforms) == 0 {
+		return nil
+	}
+	for _, p := range platforms {
+		if p == runtime.GOOS {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s 仅支持 %s 系统", c.Description(), platformNames(platforms))
+}
+
+// HelpList 生成帮助信息中的组件列表，format 为每个组件的说明格式，如 "备份 %s 配置"
+func HelpList(op Operation, format string) string {
+	var b strings.Builder
+	for _, c := range All(op) {
+		fmt.Fprintf(&b, "  - %s: "+format, c.Name(), c.Description())
+		if platforms := c.Platforms(); len(platforms) > 0 {
+			fmt.Fprintf(&b, " (%s)", platformNames(platforms))
+		}
+		b.WriteString("\n")
+	}
+	// 帮助信息在加载配置文件之前生成，其中的组件只能笼统说明
+	if (custom{}).Supports(op) {
+		b.WriteString("  - 配置文件中 components 声明的组件\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// platformNames 返回操作系统的显示名称
+func platformNames(platforms []string) string {
+	names := make([]string, 0, len(platforms))
+	for _, p := range platforms {
+		switch p {
+		case "windows":
+			names = append(names, "Windows")
+		case "linux":
+			names = append(names, "Linux")
+		case "darwin":
+			names = append(names, "macOS")
+		default:
+			names = append(names, p)
+		}
+	}
+	return strings.Join(names, "/")
+}
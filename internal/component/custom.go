@@ -0,0 +1,111 @@
+package component
+
+import (
+	"fmt"
+	"os"
+
+	"qs-tools/internal/config"
+	"qs-tools/internal/manifest"
+	"qs-tools/internal/utils"
+)
+
+// custom 在配置文件中声明的组件，由一组文件和目录组成，只支持备份和恢复
+type custom struct {
+	name string
+	cfg  config.ComponentConfig
+}
+
+func (c custom) Name() string { return c.name }
+
+func (c custom) Description() string {
+	if c.cfg.Description != "" {
+		return c.cfg.Description
+	}
+	return c.name
+}
+
+func (custom) Platforms() []string { return nil }
+
+// Supports 自定义组件不支持安装
+func (custom) Supports(op Operation) bool {
+	return op != OpInstall
+}
+
+func (c custom) ConfigPaths() ([]string, error) {
+	targets, err := c.targets()
+	if err != nil {
+		return nil, err
+	}
+	return targets.Paths(), nil
+}
+
+// targets 按配置文件中的路径生成备份目标
+func (c custom) targets() (manifest.Targets, error) {
+	var targets manifest.Targets
+	for _, p := range c.cfg.Paths {
+		path, err := p.LocalPath()
+		if err != nil {
+			return nil, fmt.Errorf("组件 %s 的路径 %s 无效: %v", c.name, p.ArchiveName(), err)
+		}
+		targets = append(targets, manifest.Target{
+			Name:     p.ArchiveName(),
+			Path:     path,
+			Excludes: p.Excludes,
+		})
+	}
+	return targets, nil
+}
+
+func (c custom) Install() error {
+	return ErrNotSupported
+}
+
+func (c custom) Backup(opts utils.BackupOptions) (*utils.BackupResult, error) {
+	targets, err := c.targets()
+	if err != nil {
+		return nil, err
+	}
+	return backupTargets(c, targets, "", opts)
+}
+
+func (c custom) Apply(opts utils.ApplyOptions) error {
+	targets, err := c.targets()
+	if err != nil {
+		return err
+	}
+	return applyTargets(c, targets, opts)
+}
+
+// Verify 至少有一个路径存在即视为可用
+func (c custom) Verify() error {
+	paths, err := c.ConfigPaths()
+	if err != nil {
+		return err
+	}
+	for _, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s 配置不存在", c.Description())
+}
+
+// validate 检查配置文件中的组件定义
+func (c custom) validate() error {
+	if len(c.cfg.Paths) == 0 {
+		return fmt.Errorf("组件 %s 没有配置路径", c.name)
+	}
+
+	names := make(map[string]bool)
+	for _, p := range c.cfg.Paths {
+		name := p.ArchiveName()
+		if name == "" || name == "." || name == "/" {
+			return fmt.Errorf("组件 %s 的路径缺少名称", c.name)
+		}
+		if names[name] {
+			return fmt.Errorf("组件 %s 中的名称重复: %s", c.name, name)
+		}
+		names[name] = true
+	}
+	return nil
+}
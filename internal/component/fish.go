@@ -0,0 +1,421 @@
+package component
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"qs-tools/internal/config"
+	"qs-tools/internal/manifest"
+	"qs-tools/internal/utils"
+)
+
+func init() {
+	Register(fish{})
+}
+
+// fish Fish Shell 组件
+type fish struct{}
+
+func (fish) Name() string        { return "fish" }
+func (fish) Description() string { return "Fish Shell" }
+func (fish) Platforms() []string { return []string{"linux", "darwin"} }
+
+func (f fish) ConfigPaths() ([]string, error) {
+	targets, err := f.targets()
+	if err != nil {
+		return nil, err
+	}
+	return targets.Paths(), nil
+}
+
+// fishConfigFiles 备份的 fish 配置，fish_variables 恢复时与本地的全局变量合并
+var fishConfigFiles = []string{"config.fish", "fish_plugins", "functions", "conf.d", "completions", "fish_variables"}
+
+// fisherURL fisher 插件管理器的安装脚本
+const fisherURL = "https://raw.githubusercontent.com/jorgebucaran/fisher/main/functions/fisher.fish"
+
+// targets 分别备份 fish 配置目录（$XDG_CONFIG_HOME/fish，默认为 ~/.config/fish）中的
+// config.fish、fish_plugins、fish_variables 和用户的函数、conf.d、补全，
+// 由 fisher 安装的插件文件不备份，恢复时按 fish_plugins 重新安装
+func (fish) targets() (manifest.Targets, error) {
+	configDir, err := utils.ComponentConfigDir("fish")
+	if err != nil {
+		return nil, err
+	}
+
+	plugins := fisherFiles(configDir)
+	var targets manifest.Targets
+	for _, name := range fishConfigFiles {
+		t := manifest.Target{Name: name, Path: filepath.Join(configDir, name)}
+		for _, f := range plugins {
+			if rel, ok := strings.CutPrefix(f, name+"/"); ok {
+				t.Excludes = append(t.Excludes, escapeGlob(rel))
+			}
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// fisherFiles 返回 fisher 安装的插件文件相对配置目录的路径，
+// fisher 在全局变量 _fisher_<plugin>_files 中记录每个插件安装的文件
+func fisherFiles(configDir string) []string {
+	data, err := os.ReadFile(filepath.Join(configDir, "fish_variables"))
+	if err != nil {
+		return nil
+	}
+	vars, err := parseFishVariables(data)
+	if err != nil {
+		fmt.Printf("⚠️ %v，无法识别 fisher 安装的插件文件\n", err)
+		return nil
+	}
+
+	var files []string
+	for _, v := range vars {
+		if !strings.HasPrefix(v.Name, "_fisher_") || !strings.HasSuffix(v.Name, "_files") {
+			continue
+		}
+		for _, path := range v.Values {
+			path, err := config.ExpandPath(path)
+			if err != nil {
+				continue
+			}
+			rel, err := filepath.Rel(configDir, path)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+	}
+	return files
+}
+
+// escapeGlob 转义 glob 模式中的特殊字符，使其只匹配文件名本身
+func escapeGlob(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if strings.ContainsRune(`*?[\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (f fish) Backup(opts utils.BackupOptions) (*utils.BackupResult, error) {
+	targets, err := f.targets()
+	if err != nil {
+		return nil, err
+	}
+	return backupTargets(f, targets, utils.ToolVersion("fish", "--version"), opts)
+}
+
+// Apply 恢复配置，fish_variables 按规则与本地的全局变量合并，之后按 fish_plugins 安装插件
+func (f fish) Apply(opts utils.ApplyOptions) error {
+	targets, err := f.targets()
+	if err != nil {
+		return err
+	}
+	configDir, err := utils.ComponentConfigDir("fish")
+	if err != nil {
+		return err
+	}
+	rules, err := fishVarRules()
+	if err != nil {
+		return err
+	}
+
+	if opts.DryRun {
+		// 恢复到其他目录时不合并全局变量，直接预览文件的变化
+		if opts.Target != "" {
+			return previewTargets(f, targets, opts, nil)
+		}
+		return previewTargets(f, targets, opts, func(changes []utils.FileChange) ([]utils.FileChange, error) {
+			return previewFishVariables(changes, rules)
+		})
+	}
+	if opts.Target != "" {
+		return applyTargets(f, targets, opts)
+	}
+
+	// 恢复前读取本地的全局变量，恢复后与备份中的合并
+	varsPath := filepath.Join(configDir, "fish_variables")
+	before, err := os.ReadFile(varsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("读取 fish_variables 失败: %v", err)
+	}
+	local, err := parseFishVariables(before)
+	if err != nil {
+		return err
+	}
+	if err := applyTargets(f, targets, opts); err != nil {
+		return err
+	}
+	if err := mergeFishVariablesFile(varsPath, before, local, rules); err != nil {
+		return err
+	}
+	// 合并改写了恢复的 fish_variables，同步记录中记录合并后的哈希，
+	// 否则合并的结果会被当作本地修改，状态显示为有修改，下次备份也会重新上传
+	if opts.RecordsSync() {
+		if err := utils.RecordLocalChanges(f.Name(), targets, "fish_variables"); err != nil {
+			fmt.Printf("⚠️ %v\n", err)
+		}
+	}
+
+	if len(opts.Only) > 0 && !manifest.Match(opts.Only, "fish_plugins") {
+		return nil
+	}
+	return updateFishPlugins(configDir)
+}
+
+// fishVarRules 返回配置文件中 fish 组件的全局变量合并规则
+func fishVarRules() (map[string]fishVarRule, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := parseFishVarRules(cfg.Component("fish").Variables)
+	if err != nil {
+		return nil, fmt.Errorf("配置文件中 fish 的 variables 无效: %v", err)
+	}
+	return rules, nil
+}
+
+// mergeFishVariablesFile 恢复的 fish_variables 与恢复前不同时，将其与恢复前本地的全局变量合并后写回
+func mergeFishVariablesFile(path string, before []byte, local []fishVar, rules map[string]fishVarRule) error {
+	after, err := os.ReadFile(path)
+	if err != nil || bytes.Equal(before, after) {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	remote, err := parseFishVariables(after)
+	if err != nil {
+		return err
+	}
+
+	merged, changes := mergeFishVariables(local, remote, rules)
+	if err := os.WriteFile(path, formatFishVariables(merged), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("写入 fish_variables 失败: %v", err)
+	}
+	printFishVarChanges(changes)
+	return nil
+}
+
+// previewFishVariables 预览时将 fish_variables 的变化替换为合并后的结果
+func previewFishVariables(changes []utils.FileChange, rules map[string]fishVarRule) ([]utils.FileChange, error) {
+	result := changes[:0]
+	for _, c := range changes {
+		if c.Path != "fish_variables" || c.Kind == utils.ChangeDeleted {
+			result = append(result, c)
+			continue
+		}
+
+		local, err := parseFishVariables(c.Local)
+		if err != nil {
+			return nil, err
+		}
+		remote, err := parseFishVariables(c.Remote)
+		if err != nil {
+			return nil, err
+		}
+		merged, varChanges := mergeFishVariables(local, remote, rules)
+		if len(varChanges) == 0 {
+			continue
+		}
+		printFishVarChanges(varChanges)
+		c.Remote = formatFishVariables(merged)
+		if c.Kind == utils.ChangeModified {
+			c.RemoteMode = c.LocalMode
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+// updateFishPlugins 通过 fisher update 安装 fish_plugins 中的插件并移除其他插件，没有 fisher 时先安装 fisher
+func updateFishPlugins(configDir string) error {
+	if _, err := os.Stat(filepath.Join(configDir, "fish_plugins")); err != nil {
+		return nil
+	}
+	if err := verifyCommand("fish"); err != nil {
+		return fmt.Errorf("%v，无法安装插件，请先通过 qs-tools install fish 安装", err)
+	}
+
+	script := "fisher update"
+	if exec.Command("fish", "-c", "functions -q fisher").Run() != nil {
+		fmt.Println("\n未安装 fisher，正在安装...")
+		script = fmt.Sprintf("curl -sL %s | source && fisher update", fisherURL)
+	}
+
+	fmt.Println("\n按 fish_plugins 安装插件...")
+	cmd := exec.Command("fish", "-c", script)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("安装插件失败: %v", err)
+	}
+	return nil
+}
+
+func (fish) Verify() error {
+	return verifyCommand("fish")
+}
+
+// Install 安装 Fish Shell，只支持基于 Debian 的系统，配置的备份和恢复不受此限制
+func (fish) Install() error {
+	if runtime.GOOS == "darwin" {
+		return fmt.Errorf("macOS 上请通过 Homebrew 安装: brew install fish")
+	}
+	// 检查是否为支持的系统
+	if !isDebianBased() {
+		return fmt.Errorf("当前系统不是基于 Debian 的系统（如 Ubuntu、Debian、Kylin 等）")
+	}
+
+	fmt.Println("检测到支持的系统，开始安装 Fish Shell...")
+
+	// 如果是麒麟系统，使用直接下载安装的方式
+	if isKylin() {
+		fmt.Println("\n检测到 Kylin 系统，将使用直接下载安装方式...")
+
+		// 创建临时目录
+		tmpDir := "/tmp/qs-tools-fish"
+		os.MkdirAll(tmpDir, 0755)
+		debPath := tmpDir + "/fish.deb"
+
+		// 下载 deb 包 (使用 3.7.1 版本)
+		fmt.Println("\n1. 下载 Fish Shell 安装包...")
+		downloadCmd := exec.Command("wget",
+			"https://download.opensuse.org/repositories/shells:/fish:/release:/3/Debian_10/amd64/fish_3.7.1-1_amd64.deb",
+			"-O", debPath)
+		downloadCmd.Stdout = os.Stdout
+		downloadCmd.Stderr = os.Stderr
+		if err := downloadCmd.Run(); err != nil {
+			fmt.Printf("下载安装包失败: %v\n", err)
+			fmt.Println("\n尝试安装系统默认版本...")
+			// 如果下载失败，尝试使用系统默认源安装
+			return installFishFromApt()
+		}
+
+		// 安装 deb 包前先安装依赖
+		fmt.Println("\n2. 安装必要的依赖...")
+		depsCmd := exec.Command("sudo", "apt-get", "install", "-y", "libpcre2-32-0")
+		depsCmd.Stdout = os.Stdout
+		depsCmd.Stderr = os.Stderr
+		depsCmd.Run() // 忽略错误，让后续的 apt-get install -f 处理
+
+		// 安装 deb 包
+		fmt.Println("\n3. 安装 Fish Shell...")
+		installCmd := exec.Command("sudo", "dpkg", "-i", debPath)
+		installCmd.Stdout = os.Stdout
+		installCmd.Stderr = os.Stderr
+		if err := installCmd.Run(); err != nil {
+			fmt.Println("\n安装过程中可能缺少依赖，尝试修复...")
+			fixCmd := exec.Command("sudo", "apt-get", "install", "-f", "-y")
+			fixCmd.Stdout = os.Stdout
+			fixCmd.Stderr = os.Stderr
+			if err := fixCmd.Run(); err != nil {
+				fmt.Printf("修复依赖失败: %v\n", err)
+				fmt.Println("\n尝试安装系统默认版本...")
+				// 如果安装失败，尝试使用系统默认源安装
+				return installFishFromApt()
+			}
+
+			// 重试安装
+			if err := installCmd.Run(); err != nil {
+				fmt.Printf("安装失败: %v\n", err)
+				fmt.Println("\n尝试安装系统默认版本...")
+				// 如果安装失败，尝试使用系统默认源安装
+				return installFishFromApt()
+			}
+		}
+
+		// 清理临时文件
+		os.RemoveAll(tmpDir)
+	} else if err := installFishFromApt(); err != nil {
+		return err
+	}
+
+	fmt.Println("\n✅ Fish Shell 安装成功！")
+
+	// 获取 fish 版本
+	versionCmd := exec.Command("fish", "--version")
+	versionOutput, err := versionCmd.Output()
+	if err == nil {
+		fmt.Printf("\n当前安装的版本：%s", string(versionOutput))
+	}
+
+	fmt.Println("\n你可以通过以下命令将 Fish 设置为默认 Shell：")
+	fmt.Println("chsh -s $(which fish)")
+
+	// 检查是否为 Kylin 系统，提供额外说明
+	if isKylin() {
+		fmt.Println("\n注意：在 Kylin 系统上，你可能需要以下额外步骤：")
+		fmt.Println("1. 编辑 /etc/shells 文件，添加 Fish Shell 路径")
+		fmt.Println("   sudo echo $(which fish) >> /etc/shells")
+		fmt.Println("2. 然后再执行更改默认 Shell 的命令")
+	}
+
+	fmt.Println("\n💡 提示：首次启动 Fish Shell 时，建议运行以下命令完成初始配置：")
+	fmt.Println("fish_config")
+	return nil
+}
+
+// installFishFromApt 使用 apt 安装 Fish Shell
+func installFishFromApt() error {
+	// 其他 Debian 系统使用 PPA 安装
+	needPPA := true
+
+	// 检查并安装必要的依赖
+	fmt.Println("\n1. 检查并安装必要的依赖...")
+	checkDepsCmd := exec.Command("which", "apt-add-repository")
+	if err := checkDepsCmd.Run(); err != nil {
+		fmt.Println("正在安装 software-properties-common...")
+		installDepsCmd := exec.Command("sudo", "apt", "install", "-y", "software-properties-common")
+		installDepsCmd.Stdout = os.Stdout
+		installDepsCmd.Stderr = os.Stderr
+		if err := installDepsCmd.Run(); err != nil {
+			fmt.Printf("安装依赖失败: %v\n", err)
+			fmt.Println("将尝试使用系统默认软件源安装 Fish Shell...")
+			needPPA = false
+		}
+	}
+
+	// 如果需要且可以添加 PPA，则添加
+	if needPPA {
+		fmt.Println("\n2. 添加 Fish Shell 官方 PPA...")
+		addRepoCmd := exec.Command("sudo", "apt-add-repository", "-y", "ppa:fish-shell/release-3")
+		addRepoCmd.Stdout = os.Stdout
+		addRepoCmd.Stderr = os.Stderr
+		if err := addRepoCmd.Run(); err != nil {
+			fmt.Printf("添加 Fish Shell PPA 失败: %v\n", err)
+			fmt.Println("将尝试使用系统默认软件源安装...")
+		}
+	}
+
+	// 更新包索引
+	fmt.Println("\n3. 更新软件包索引...")
+	updateCmd := exec.Command("sudo", "apt", "update")
+	updateCmd.Stdout = os.Stdout
+	updateCmd.Stderr = os.Stderr
+	if err := updateCmd.Run(); err != nil {
+		return fmt.Errorf("更新包索引失败: %v", err)
+	}
+
+	// 安装 fish
+	fmt.Println("\n4. 安装 Fish Shell...")
+	installCmd := exec.Command("sudo", "apt", "install", "-y", "fish")
+	installCmd.Stdout = os.Stdout
+	installCmd.Stderr = os.Stderr
+	if err := installCmd.Run(); err != nil {
+		return fmt.Errorf("安装 Fish Shell 失败: %v", err)
+	}
+	return nil
+} 
@@ -0,0 +1,307 @@
+package component
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// fishVarSeparator fish_variables 中列表元素的分隔符
+const fishVarSeparator = "\x1e"
+
+// fishVarHeader fish 写入 fish_variables 时的文件头
+const fishVarHeader = "# This file contains fish universal variable definitions.\n# VERSION: 3.0\n"
+
+// fishVarRule 恢复时全局变量的合并规则
+type fishVarRule string
+
+const (
+	// fishVarRemoteWins 使用备份中的值
+	fishVarRemoteWins fishVarRule = "remote-wins"
+	// fishVarLocalWins 本地已有该变量时保留本地的值，没有时使用备份中的值
+	fishVarLocalWins fishVarRule = "local-wins"
+	// fishVarExcluded 不恢复备份中的值
+	fishVarExcluded fishVarRule = "excluded"
+)
+
+// defaultFishVarRules 默认的合并规则，键为变量名的 glob 模式，其他变量使用备份中的值。
+// 路径、fisher 记录的已安装插件和 fish 内部的变量只与当前主机有关
+var defaultFishVarRules = map[string]fishVarRule{
+	"fish_user_paths": fishVarExcluded,
+	"_fisher_*":       fishVarExcluded,
+	"__fish_*":        fishVarExcluded,
+}
+
+// fishVar fish_variables 中的一个全局变量（universal variable）
+type fishVar struct {
+	Name   string
+	Values []string
+	// Export 是否导出为环境变量（set -Ux）
+	Export bool
+	// Path 是否为路径变量（set -U --path）
+	Path bool
+}
+
+// parseFishVariables 解析 fish_variables 文件，格式为每行一个
+// "SETUVAR [--export] [--path] NAME:VALUE"，注释和空行会被忽略
+func parseFishVariables(data []byte) ([]fishVar, error) {
+	var vars []fishVar
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		rest, ok := strings.CutPrefix(text, "SETUVAR ")
+		if !ok {
+			return nil, fmt.Errorf("fish_variables 第 %d 行格式无效", line)
+		}
+		var v fishVar
+		for {
+			if r, ok := strings.CutPrefix(rest, "--export "); ok {
+				v.Export, rest = true, r
+			} else if r, ok := strings.CutPrefix(rest, "--path "); ok {
+				v.Path, rest = true, r
+			} else {
+				break
+			}
+		}
+
+		name, value, ok := strings.Cut(rest, ":")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("fish_variables 第 %d 行格式无效", line)
+		}
+		v.Name = name
+
+		unescaped, err := unescapeFishValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("fish_variables 第 %d 行: %v", line, err)
+		}
+		if unescaped != "" {
+			v.Values = strings.Split(unescaped, fishVarSeparator)
+		}
+		vars = append(vars, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取 fish_variables 失败: %v", err)
+	}
+	return vars, nil
+}
+
+// unescapeFishValue 还原 fish 转义后的变量值，列表元素之间保留分隔符 \x1e
+func unescapeFishValue(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		if i+1 >= len(s) {
+			return "", fmt.Errorf("转义不完整: %s", s)
+		}
+		i++
+		switch s[i] {
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'e':
+			b.WriteByte(0x1b)
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'v':
+			b.WriteByte('\v')
+		case 'x', 'X', 'u', 'U':
+			digits := map[byte]int{'x': 2, 'X': 2, 'u': 4, 'U': 8}[s[i]]
+			end := i + 1
+			for end < len(s) && end-i-1 < digits && isHexDigit(s[end]) {
+				end++
+			}
+			if end == i+1 {
+				return "", fmt.Errorf("无效的转义: %s", s)
+			}
+			n, _ := strconv.ParseUint(s[i+1:end], 16, 32)
+			if s[i] == 'x' || s[i] == 'X' {
+				b.WriteByte(byte(n))
+			} else {
+				b.WriteRune(rune(n))
+			}
+			i = end - 1
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+// escapeFishValue 按 fish 写入 fish_variables 的方式转义变量值：
+// 字母、数字、"/" 和 "_" 原样保留，其他字符转义为 \xHH、\uHHHH 或 \UHHHHHHHH
+func escapeFishValue(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '/', r == '_':
+			b.WriteRune(r)
+		case r < 0x80:
+			fmt.Fprintf(&b, "\\x%.2x", r)
+		case r < 0x10000:
+			fmt.Fprintf(&b, "\\u%.4x", r)
+		default:
+			fmt.Fprintf(&b, "\\U%.8x", r)
+		}
+	}
+	return b.String()
+}
+
+// formatFishVariables 生成 fish_variables 文件的内容
+func formatFishVariables(vars []fishVar) []byte {
+	var b bytes.Buffer
+	b.WriteString(fishVarHeader)
+	for _, v := range vars {
+		b.WriteString("SETUVAR")
+		if v.Export {
+			b.WriteString(" --export")
+		}
+		if v.Path {
+			b.WriteString(" --path")
+		}
+		fmt.Fprintf(&b, " %s:%s\n", v.Name, escapeFishValue(strings.Join(v.Values, fishVarSeparator)))
+	}
+	return b.Bytes()
+}
+
+// parseFishVarRules 解析配置文件中的合并规则，与默认规则合并，配置文件中的规则优先
+func parseFishVarRules(rules map[string]string) (map[string]fishVarRule, error) {
+	merged := make(map[string]fishVarRule, len(defaultFishVarRules)+len(rules))
+	for pattern, rule := range defaultFishVarRules {
+		merged[pattern] = rule
+	}
+	for pattern, rule := range rules {
+		switch r := fishVarRule(rule); r {
+		case fishVarRemoteWins, fishVarLocalWins, fishVarExcluded:
+			merged[pattern] = r
+		default:
+			return nil, fmt.Errorf("变量 %s 的合并规则无效: %s（可选 remote-wins、local-wins、excluded）", pattern, rule)
+		}
+	}
+	return merged, nil
+}
+
+// ruleFor 返回变量的合并规则，变量名完全相同的规则优先，其次是最长的匹配模式
+func ruleFor(rules map[string]fishVarRule, name string) fishVarRule {
+	if rule, ok := rules[name]; ok {
+		return rule
+	}
+	best, rule := "", fishVarRemoteWins
+	for pattern, r := range rules {
+		if ok, _ := path.Match(pattern, name); ok && len(pattern) > len(best) {
+			best, rule = pattern, r
+		}
+	}
+	return rule
+}
+
+// fishVarChange 合并后全局变量的变化
+type fishVarChange struct {
+	Name string
+	// Old、New 合并前后的值，新增的变量 Old 为 nil
+	Old, New *fishVar
+}
+
+// mergeFishVariables 按规则将备份中的全局变量合并到本地的变量中。
+// 只在本地存在的变量保持不变，返回合并结果和有变化的变量
+func mergeFishVariables(local, remote []fishVar, rules map[string]fishVarRule) ([]fishVar, []fishVarChange) {
+	merged := append([]fishVar(nil), local...)
+	index := make(map[string]int, len(merged))
+	for i, v := range merged {
+		index[v.Name] = i
+	}
+
+	var changes []fishVarChange
+	for _, r := range remote {
+		i, exists := index[r.Name]
+		switch ruleFor(rules, r.Name) {
+		case fishVarExcluded:
+			continue
+		case fishVarLocalWins:
+			if exists {
+				continue
+			}
+		}
+
+		r := r
+		if !exists {
+			index[r.Name] = len(merged)
+			merged = append(merged, r)
+			changes = append(changes, fishVarChange{Name: r.Name, New: &r})
+			continue
+		}
+		old := merged[i]
+		if sameFishVar(old, r) {
+			continue
+		}
+		merged[i] = r
+		changes = append(changes, fishVarChange{Name: r.Name, Old: &old, New: &r})
+	}
+	return merged, changes
+}
+
+func sameFishVar(a, b fishVar) bool {
+	if a.Export != b.Export || a.Path != b.Path || len(a.Values) != len(b.Values) {
+		return false
+	}
+	for i := range a.Values {
+		if a.Values[i] != b.Values[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// printFishVarChanges 打印合并后有变化的全局变量
+func printFishVarChanges(changes []fishVarChange) {
+	if len(changes) == 0 {
+		fmt.Println("fish 全局变量没有变化")
+		return
+	}
+	fmt.Println("fish 全局变量的变化：")
+	for _, c := range changes {
+		if c.Old == nil {
+			fmt.Printf("  + %s = %s\n", c.Name, displayFishValues(c.New.Values))
+			continue
+		}
+		fmt.Printf("  ~ %s: %s -> %s\n", c.Name, displayFishValues(c.Old.Values), displayFishValues(c.New.Values))
+	}
+}
+
+// displayFishValues 返回用于显示的变量值，过长时截断
+func displayFishValues(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, strconv.Quote(v))
+	}
+	s := strings.Join(quoted, " ")
+	if runes := []rune(s); len(runes) > 80 {
+		s = string(runes[:77]) + "..."
+	}
+	if s == "" {
+		return "(空)"
+	}
+	return s
+}
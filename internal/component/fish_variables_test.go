@@ -0,0 +1,245 @@
+package component
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readFishVariables 读取 testdata 中的 fish_variables 并解析
+func readFishVariables(t *testing.T) ([]byte, []fishVar) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "fish_variables"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars, err := parseFishVariables(data)
+	if err != nil {
+		t.Fatalf("解析 fish_variables 失败: %v", err)
+	}
+	return data, vars
+}
+
+func TestParseFishVariables(t *testing.T) {
+	data, vars := readFishVariables(t)
+	if len(vars) != 10 {
+		t.Fatalf("解析出 %d 个变量，应为 10 个", len(vars))
+	}
+
+	byName := make(map[string]fishVar)
+	for _, v := range vars {
+		byName[v.Name] = v
+	}
+	tests := []struct {
+		name   string
+		values []string
+		export bool
+		path   bool
+	}{
+		{name: "EDITOR", values: []string{"nvim"}, export: true},
+		{name: "LIBRARY_PATH", values: []string{"/usr/local/lib", "/opt/lib"}, export: true, path: true},
+		{name: "fish_color_autosuggestion", values: []string{"555", "brblack"}},
+		{name: "fish_color_cancel", values: []string{"-r"}},
+		{name: "fish_greeting", values: []string{"欢迎 回来"}},
+		{name: "GREETING_EMOJI", values: []string{"🐟"}},
+		{name: "_fisher_jorgebucaran_2F_fisher_files", values: []string{"~/.config/fish/functions/fisher.fish"}},
+	}
+	for _, tt := range tests {
+		v, ok := byName[tt.name]
+		if !ok {
+			t.Fatalf("缺少变量 %s", tt.name)
+		}
+		if !reflect.DeepEqual(v.Values, tt.values) || v.Export != tt.export || v.Path != tt.path {
+			t.Errorf("%s: 值为 %q export=%v path=%v，应为 %q %v %v", tt.name,
+				v.Values, v.Export, v.Path, tt.values, tt.export, tt.path)
+		}
+	}
+
+	// 重新生成的内容与 fish 写入的文件相同
+	if got := formatFishVariables(vars); string(got) != string(data) {
+		t.Fatalf("重新生成的内容不同:\n%s\n%s", data, got)
+	}
+}
+
+func TestParseFishVariablesInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "不是 SETUVAR", data: "set -U foo bar\n", want: "第 1 行格式无效"},
+		{name: "缺少冒号", data: "# VERSION: 3.0\nSETUVAR foo\n", want: "第 2 行格式无效"},
+		{name: "缺少变量名", data: "SETUVAR --export :bar\n", want: "第 1 行格式无效"},
+		{name: "转义不完整", data: "SETUVAR foo:bar\\\n", want: "转义不完整"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseFishVariables([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("错误为 %v，应包含 %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnescapeFishValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		escaped string
+		want    string
+		wantErr bool
+	}{
+		{name: "不需要转义", escaped: "nvim", want: "nvim"},
+		{name: "列表分隔符", escaped: `555\x1ebrblack`, want: "555" + fishVarSeparator + "brblack"},
+		{name: "\\x 只取两位", escaped: `\x41BC`, want: "ABC"},
+		{name: "大写 \\X", escaped: `\X2e`, want: "."},
+		{name: "\\u", escaped: `\u00e9t\u00e9`, want: "été"},
+		{name: "\\U", escaped: `\U0001f41f`, want: "🐟"},
+		{name: "控制字符", escaped: `a\tb\nc\e`, want: "a\tb\nc\x1b"},
+		{name: "其他字符原样保留", escaped: `\\\'`, want: `\'`},
+		{name: "转义不完整", escaped: `abc\`, wantErr: true},
+		{name: "缺少十六进制数字", escaped: `\xzz`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := unescapeFishValue(tt.escaped)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("应返回错误，实际为 %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("还原为 %q，应为 %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeFishValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "字母数字和路径", value: "/usr/local/bin_2", want: "/usr/local/bin_2"},
+		{name: "ASCII 符号", value: "~/.local bin", want: `\x7e/\x2elocal\x20bin`},
+		{name: "列表分隔符", value: "a" + fishVarSeparator + "b", want: `a\x1eb`},
+		{name: "BMP 字符", value: "欢迎", want: `\u6b22\u8fce`},
+		{name: "BMP 以外的字符", value: "🐟", want: `\U0001f41f`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := escapeFishValue(tt.value)
+			if got != tt.want {
+				t.Fatalf("转义为 %q，应为 %q", got, tt.want)
+			}
+			back, err := unescapeFishValue(got)
+			if err != nil || back != tt.value {
+				t.Fatalf("还原为 %q (%v)，应为 %q", back, err, tt.value)
+			}
+		})
+	}
+}
+
+func TestRuleFor(t *testing.T) {
+	rules, err := parseFishVarRules(map[string]string{
+		"fish_*":             "excluded",
+		"fish_color_*":       "local-wins",
+		"fish_color_cancel":  "remote-wins",
+		"__fish_initialized": "remote-wins",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		want fishVarRule
+	}{
+		// 默认规则
+		{name: "fish_user_paths", want: fishVarExcluded},
+		{name: "_fisher_plugins", want: fishVarExcluded},
+		{name: "__fish_other", want: fishVarExcluded},
+		{name: "EDITOR", want: fishVarRemoteWins},
+		// 变量名完全相同的规则优先于匹配的模式
+		{name: "__fish_initialized", want: fishVarRemoteWins},
+		{name: "fish_color_cancel", want: fishVarRemoteWins},
+		// 最长的匹配模式优先
+		{name: "fish_color_autosuggestion", want: fishVarLocalWins},
+		{name: "fish_greeting", want: fishVarExcluded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ruleFor(rules, tt.name); got != tt.want {
+				t.Fatalf("规则为 %s，应为 %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := parseFishVarRules(map[string]string{"EDITOR": "keep"}); err == nil {
+		t.Fatal("无效的规则应返回错误")
+	}
+}
+
+func TestMergeFishVariables(t *testing.T) {
+	_, remote := readFishVariables(t)
+	local := []fishVar{
+		{Name: "EDITOR", Values: []string{"vim"}, Export: true},
+		{Name: "fish_color_autosuggestion", Values: []string{"brblack"}},
+		{Name: "fish_color_cancel", Values: []string{"-r"}},
+		{Name: "fish_user_paths", Values: []string{"/opt/homebrew/bin"}},
+		{Name: "LOCAL_ONLY", Values: []string{"1"}},
+	}
+	rules, err := parseFishVarRules(map[string]string{
+		"fish_color_*":   "local-wins",
+		"GREETING_EMOJI": "excluded",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	merged, changes := mergeFishVariables(local, remote, rules)
+
+	values := make(map[string][]string)
+	for _, v := range merged {
+		values[v.Name] = v.Values
+	}
+	tests := []struct {
+		name string
+		want []string // nil 表示合并后没有该变量
+	}{
+		{name: "EDITOR", want: []string{"nvim"}},                             // remote-wins
+		{name: "fish_color_autosuggestion", want: []string{"brblack"}},       // local-wins，本地已有
+		{name: "fish_user_paths", want: []string{"/opt/homebrew/bin"}},       // 默认排除
+		{name: "LOCAL_ONLY", want: []string{"1"}},                            // 只在本地存在
+		{name: "LIBRARY_PATH", want: []string{"/usr/local/lib", "/opt/lib"}}, // 本地没有，新增
+		{name: "GREETING_EMOJI"},                                             // 配置中排除
+		{name: "_fisher_plugins"},                                            // 默认排除
+		{name: "__fish_initialized"},                                         // 默认排除
+	}
+	for _, tt := range tests {
+		if got := values[tt.name]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s 合并后为 %q，应为 %q", tt.name, got, tt.want)
+		}
+	}
+
+	var changed []string
+	for _, c := range changes {
+		if c.Old == nil {
+			changed = append(changed, "+"+c.Name)
+		} else {
+			changed = append(changed, "~"+c.Name)
+		}
+	}
+	// fish_color_* 本地已有时保留本地的值，不算变化
+	want := []string{"~EDITOR", "+LIBRARY_PATH", "+fish_greeting"}
+	if !reflect.DeepEqual(changed, want) {
+		t.Fatalf("变化为 %v，应为 %v", changed, want)
+	}
+}
@@ -0,0 +1,161 @@
+package component
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"qs-tools/internal/config"
+	"qs-tools/internal/utils"
+)
+
+// Phase 执行钩子的阶段
+type Phase string
+
+const (
+	// PreBackup 备份前，失败时取消备份
+	PreBackup Phase = "pre-backup"
+	// PostBackup 备份成功后
+	PostBackup Phase = "post-backup"
+	// PreApply 恢复前，失败时取消恢复
+	PreApply Phase = "pre-apply"
+	// PostApply 恢复成功后
+	PostApply Phase = "post-apply"
+	// PostInstall 安装成功后
+	PostInstall Phase = "post-install"
+)
+
+// Phases 全部钩子阶段
+var Phases = []Phase{PreBackup, PostBackup, PreApply, PostApply, PostInstall}
+
+// Backup 备份组件配置，并在前后执行配置文件中的钩子
+func Backup(c Component, opts utils.BackupOptions) (*utils.BackupResult, error) {
+	env := map[string]string{
+		"QS_TOOLS_FULL":  strconv.FormatBool(opts.Full),
+		"QS_TOOLS_FORCE": strconv.FormatBool(opts.Force),
+		"QS_TOOLS_TAG":   opts.Tag,
+	}
+	if err := runHook(c, PreBackup, env); err != nil {
+		return nil, err
+	}
+
+	result, err := c.Backup(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	env["QS_TOOLS_VERSION"] = result.Meta.ID
+	env["QS_TOOLS_UNCHANGED"] = strconv.FormatBool(result.Unchanged)
+	warnHook(runHook(c, PostBackup, env))
+	return result, nil
+}
+
+// Apply 恢复组件配置，并在前后执行配置文件中的钩子。预览时不执行钩子
+func Apply(c Component, opts utils.ApplyOptions) error {
+	if opts.DryRun {
+		return c.Apply(opts)
+	}
+
+	env := map[string]string{
+		"QS_TOOLS_VERSION":  opts.Version,
+		"QS_TOOLS_CONFLICT": string(opts.Conflict),
+		"QS_TOOLS_ONLY":     strings.Join(opts.Only, ","),
+		"QS_TOOLS_TARGET":   opts.Target,
+	}
+	if !opts.At.IsZero() {
+		env["QS_TOOLS_AT"] = opts.At.Format("2006-01-02 15:04:05")
+	}
+	if err := runHook(c, PreApply, env); err != nil {
+		return err
+	}
+
+	if err := c.Apply(opts); err != nil {
+		return err
+	}
+
+	warnHook(runHook(c, PostApply, env))
+	return nil
+}
+
+// Install 安装组件，成功后执行配置文件中的钩子
+func Install(c Component) error {
+	if err := c.Install(); err != nil {
+		return err
+	}
+
+	warnHook(runHook(c, PostInstall, nil))
+	return nil
+}
+
+// runHook 执行组件在指定阶段的钩子，没有配置钩子时直接返回。
+// 钩子通过环境变量获得组件名称、阶段、配置路径和操作的参数
+func runHook(c Component, phase Phase, env map[string]string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	script := strings.TrimSpace(cfg.Component(c.Name()).Hooks[string(phase)])
+	if script == "" {
+		return nil
+	}
+
+	fmt.Printf("执行 %s 钩子...\n", phase)
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", script)
+	} else {
+		cmd = exec.Command("sh", "-c", script)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	cmd.Env = append(cmd.Env,
+		"QS_TOOLS_COMPONENT="+c.Name(),
+		"QS_TOOLS_PHASE="+string(phase),
+		"QS_TOOLS_OS="+runtime.GOOS,
+	)
+	if paths, err := c.ConfigPaths(); err == nil {
+		cmd.Env = append(cmd.Env, "QS_TOOLS_PATHS="+strings.Join(paths, string(os.PathListSeparator)))
+	}
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s 钩子执行失败: %v", phase, err)
+	}
+	return nil
+}
+
+// warnHook 后置钩子失败时只给出警告，操作本身已经完成
+func warnHook(err error) {
+	if err != nil {
+		fmt.Printf("⚠️ %v\n", err)
+	}
+}
+
+// checkHooks 检查组件配置中的钩子阶段名称是否有效
+func checkHooks(cc config.ComponentConfig) error {
+	for name := range cc.Hooks {
+		valid := false
+		for _, p := range Phases {
+			if string(p) == name {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			names := make([]string, 0, len(Phases))
+			for _, p := range Phases {
+				names = append(names, string(p))
+			}
+			return fmt.Errorf("不支持的钩子阶段: %s（可选 %s）", name, strings.Join(names, "、"))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package component
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"qs-tools/internal/utils"
+)
+
+// lazyLockFile lazy.nvim 记录插件版本的锁文件，位于 Neovim 配置目录
+const lazyLockFile = "lazy-lock.json"
+
+// lazyLockEntry 锁文件中一个插件的版本
+type lazyLockEntry struct {
+	Branch string `json:"branch"`
+	Commit string `json:"commit"`
+}
+
+// parseLazyLock 解析 lazy-lock.json，键为插件名称
+func parseLazyLock(data []byte) (map[string]lazyLockEntry, error) {
+	lock := make(map[string]lazyLockEntry)
+	if len(data) == 0 {
+		return lock, nil
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %v", lazyLockFile, err)
+	}
+	return lock, nil
+}
+
+// shortCommit 返回用于显示的短提交号
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// previewLazyLock 预览时打印锁文件中插件版本的变化，文件变化保持不变
+func previewLazyLock(changes []utils.FileChange) ([]utils.FileChange, error) {
+	for _, c := range changes {
+		if c.Path != lazyLockFile || c.Kind == utils.ChangeDeleted {
+			continue
+		}
+		local, err := parseLazyLock(c.Local)
+		if err != nil {
+			return nil, err
+		}
+		remote, err := parseLazyLock(c.Remote)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make(map[string]bool)
+		for name := range local {
+			names[name] = true
+		}
+		for name := range remote {
+			names[name] = true
+		}
+		sorted := make([]string, 0, len(names))
+		for name := range names {
+			sorted = append(sorted, name)
+		}
+		sort.Strings(sorted)
+
+		var lines []string
+		for _, name := range sorted {
+			l, inLocal := local[name]
+			r, inRemote := remote[name]
+			switch {
+			case !inLocal:
+				lines = append(lines, fmt.Sprintf("  + %s %s", name, shortCommit(r.Commit)))
+			case !inRemote:
+				lines = append(lines, fmt.Sprintf("  - %s %s", name, shortCommit(l.Commit)))
+			case l.Commit != r.Commit:
+				lines = append(lines, fmt.Sprintf("  ~ %s %s -> %s", name, shortCommit(l.Commit), shortCommit(r.Commit)))
+			}
+		}
+		if len(lines) > 0 {
+			fmt.Println("\n恢复后插件会切换到锁定的版本：")
+			fmt.Println(strings.Join(lines, "\n"))
+		}
+	}
+	return changes, nil
+}
+
+// restoreLazyPlugins 按配置目录中的 lazy-lock.json 将插件恢复到锁定的版本，appName 为配置的 NVIM_APPNAME，
+// 完成后逐个检查插件的版本，返回未能恢复的插件数量对应的错误
+func restoreLazyPlugins(configDir, appName string) error {
+	data, err := os.ReadFile(filepath.Join(configDir, lazyLockFile))
+	if err != nil {
+		return nil
+	}
+	lock, err := parseLazyLock(data)
+	if err != nil {
+		return err
+	}
+	if len(lock) == 0 {
+		return nil
+	}
+	if err := verifyCommand("nvim"); err != nil {
+		fmt.Printf("\n⚠️ %v，跳过插件恢复，安装 Neovim 后首次启动时执行 :Lazy restore\n", err)
+		return nil
+	}
+
+	fmt.Printf("\n按 %s 恢复 %d 个插件...\n", lazyLockFile, len(lock))
+	cmd := exec.Command("nvim", "--headless", "+Lazy! restore", "+qa")
+	cmd.Env = append(os.Environ(), "NVIM_APPNAME="+appName)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("\n⚠️ 执行 Lazy restore 失败: %v\n", err)
+	}
+	fmt.Println()
+
+	root, err := lazyRoot(appName)
+	if err != nil {
+		return err
+	}
+	failures, err := verifyLazyPlugins(lock, root)
+	if err != nil {
+		fmt.Printf("⚠️ %v，无法检查插件版本\n", err)
+		return nil
+	}
+	if len(failures) == 0 {
+		fmt.Printf("✅ %d 个插件已恢复到锁定的版本\n", len(lock))
+		return nil
+	}
+
+	names := make([]string, 0, len(failures))
+	for name := range failures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Println("以下插件未能恢复到锁定的版本：")
+	for _, name := range names {
+		fmt.Printf("  ❌ %s: %s\n", name, failures[name])
+	}
+	return fmt.Errorf("%d 个插件未能恢复到锁定的版本", len(failures))
+}
+
+// lazyRoot 返回 lazy.nvim 安装插件的目录，即 Neovim 数据目录下的 lazy
+func lazyRoot(appName string) (string, error) {
+	dataDir, err := nvimDataDir(appName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "lazy"), nil
+}
+
+// verifyLazyPlugins 检查插件目录中的版本是否与锁文件一致，返回插件名称到失败原因的映射
+func verifyLazyPlugins(lock map[string]lazyLockEntry, root string) (map[string]string, error) {
+	if err := verifyCommand("git"); err != nil {
+		return nil, err
+	}
+
+	failures := make(map[string]string)
+	for name, entry := range lock {
+		dir := filepath.Join(root, name)
+		if _, err := os.Stat(dir); err != nil {
+			failures[name] = "未安装"
+			continue
+		}
+		output, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+		if err != nil {
+			failures[name] = fmt.Sprintf("无法读取版本: %v", err)
+			continue
+		}
+		if head := strings.TrimSpace(string(output)); head != entry.Commit {
+			failures[name] = fmt.Sprintf("当前版本 %s，锁定版本 %s", shortCommit(head), shortCommit(entry.Commit))
+		}
+	}
+	return failures, nil
+}
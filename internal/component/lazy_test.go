@@ -0,0 +1,150 @@
+package component
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"qs-tools/internal/utils"
+)
+
+// readLazyLock 读取 testdata 中的 lazy-lock.json
+func readLazyLock(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", lazyLockFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// captureStdout 返回 fn 执行期间写入标准输出的内容
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	fn()
+	w.Close()
+	return string(<-done)
+}
+
+func TestParseLazyLock(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		plugins int
+		wantErr bool
+	}{
+		{name: "锁文件", data: readLazyLock(t), plugins: 4},
+		{name: "空文件", data: nil, plugins: 0},
+		{name: "没有插件", data: []byte("{}\n"), plugins: 0},
+		{name: "格式无效", data: []byte(`["lazy.nvim"]`), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lock, err := parseLazyLock(tt.data)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), lazyLockFile) {
+					t.Fatalf("错误为 %v，应为解析 %s 失败", err, lazyLockFile)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(lock) != tt.plugins {
+				t.Fatalf("解析出 %d 个插件，应为 %d 个", len(lock), tt.plugins)
+			}
+		})
+	}
+
+	lock, err := parseLazyLock(readLazyLock(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry := lock["telescope.nvim"]; entry.Branch != "0.1.x" || entry.Commit != "a0bbec21143c7bc5f8bb02e0005fa0b982edc026" {
+		t.Fatalf("telescope.nvim 的版本为 %+v", entry)
+	}
+}
+
+func TestPreviewLazyLock(t *testing.T) {
+	local := readLazyLock(t)
+	// 备份中新增 mini.nvim，删除 nvim-treesitter，更新 lazy.nvim
+	remote := strings.NewReplacer(
+		`  "nvim-treesitter": { "branch": "master", "commit": "42fc28ba918343ebfd5565147a42a26580579482" },`+"\n", "",
+		"7e6c863bc7563efbdd757a310d17ebc95166cef3", "b1134ab82ee4279e31f7ddf7e34b2a99eb9b7bc9",
+		`  "lazy.nvim"`, `  "mini.nvim": { "branch": "main", "commit": "94cae4660a8b2d95dbbd56e1fbc6fcfa2716d152" },`+"\n"+`  "lazy.nvim"`,
+	).Replace(string(local))
+
+	tests := []struct {
+		name   string
+		change utils.FileChange
+		want   []string // 为空表示不应打印插件的变化
+	}{
+		{
+			name:   "修改锁文件",
+			change: utils.FileChange{Path: lazyLockFile, Kind: utils.ChangeModified, Local: local, Remote: []byte(remote)},
+			want: []string{
+				"  ~ lazy.nvim 7e6c863 -> b1134ab",
+				"  + mini.nvim 94cae46",
+				"  - nvim-treesitter 42fc28b",
+			},
+		},
+		{
+			name:   "新增锁文件",
+			change: utils.FileChange{Path: lazyLockFile, Kind: utils.ChangeAdded, Remote: local},
+			want: []string{
+				"  + gitsigns.nvim 5f808b5",
+				"  + lazy.nvim 7e6c863",
+				"  + nvim-treesitter 42fc28b",
+				"  + telescope.nvim a0bbec2",
+			},
+		},
+		{
+			name:   "删除锁文件",
+			change: utils.FileChange{Path: lazyLockFile, Kind: utils.ChangeDeleted, Local: local},
+		},
+		{
+			name:   "其他文件",
+			change: utils.FileChange{Path: "init.lua", Kind: utils.ChangeModified, Local: local, Remote: []byte(remote)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var changes []utils.FileChange
+			var err error
+			output := captureStdout(t, func() {
+				changes, err = previewLazyLock([]utils.FileChange{tt.change})
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(changes) != 1 || changes[0].Path != tt.change.Path {
+				t.Fatalf("文件变化应保持不变: %+v", changes)
+			}
+
+			var lines []string
+			for _, line := range strings.Split(output, "\n") {
+				if strings.HasPrefix(line, "  ") {
+					lines = append(lines, line)
+				}
+			}
+			if strings.Join(lines, "\n") != strings.Join(tt.want, "\n") {
+				t.Fatalf("插件的变化为:\n%s\n应为:\n%s", strings.Join(lines, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
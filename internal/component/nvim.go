@@ -1,4 +1,4 @@
-package install
+package component
 
 import (
 	"fmt"
@@ -6,13 +6,121 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+
+	"qs-tools/internal/manifest"
+	"qs-tools/internal/utils"
 )
 
-func installNvim() error {
+func init() {
+	Register(nvim{})
+}
+
+// nvimDefaultAppName 未设置 NVIM_APPNAME 时 Neovim 使用的名称
+const nvimDefaultAppName = "nvim"
+
+// nvim Neovim 编辑器组件。同一主机上可以通过 NVIM_APPNAME 使用多份配置，
+// 每份配置是一个单独的组件，默认配置为 nvim，其他配置为 nvim@<NVIM_APPNAME>
+type nvim struct {
+	// app 配置对应的 NVIM_APPNAME，为空表示默认配置
+	app string
+}
+
+// appName 返回配置对应的 NVIM_APPNAME
+func (n nvim) appName() string {
+	if n.app == "" {
+		return nvimDefaultAppName
+	}
+	return n.app
+}
+
+func (n nvim) Name() string {
+	if n.appName() == nvimDefaultAppName {
+		return "nvim"
+	}
+	return "nvim" + ProfileSeparator + n.appName()
+}
+
+func (n nvim) Description() string {
+	if n.appName() == nvimDefaultAppName {
+		return "Neovim 编辑器"
+	}
+	return fmt.Sprintf("Neovim 编辑器（%s）", n.appName())
+}
+
+func (nvim) Platforms() []string { return nil }
+
+func (n nvim) ConfigPaths() ([]string, error) {
+	targets, err := n.targets()
+	if err != nil {
+		return nil, err
+	}
+	return targets.Paths(), nil
+}
+
+// targets 备份 Neovim 配置目录 $XDG_CONFIG_HOME/<NVIM_APPNAME>，未设置 XDG_CONFIG_HOME 时
+// Windows 为 %LOCALAPPDATA%\<NVIM_APPNAME>，其他系统为 ~/.config/<NVIM_APPNAME>，
+// 其中包括 lazy.nvim 的锁文件 lazy-lock.json
+func (n nvim) targets() (manifest.Targets, error) {
+	configDir, err := nvimConfigDir(n.appName())
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Targets{{Path: configDir}}, nil
+}
+
+func (n nvim) Backup(opts utils.BackupOptions) (*utils.BackupResult, error) {
+	targets, err := n.targets()
+	if err != nil {
+		return nil, err
+	}
+	return backupTargets(n, targets, utils.ToolVersion("nvim", "--version"), opts)
+}
+
+// Apply 恢复配置后按 lazy-lock.json 将插件恢复到锁定的版本，插件恢复失败时只给出警告
+func (n nvim) Apply(opts utils.ApplyOptions) error {
+	targets, err := n.targets()
+	if err != nil {
+		return err
+	}
+	if opts.DryRun {
+		// 恢复到其他目录时不恢复插件，只预览文件的变化
+		if opts.Target != "" {
+			return previewTargets(n, targets, opts, nil)
+		}
+		return previewTargets(n, targets, opts, previewLazyLock)
+	}
+	if err := applyTargets(n, targets, opts); err != nil {
+		return err
+	}
+
+	if opts.Target != "" {
+		return nil
+	}
+	if len(opts.Only) > 0 && !manifest.Match(opts.Only, lazyLockFile) {
+		return nil
+	}
+	configDir, err := nvimConfigDir(n.appName())
+	if err != nil {
+		return err
+	}
+	// 配置文件已经恢复，插件恢复失败时只提示，不影响恢复结果和后续的钩子
+	if err := restoreLazyPlugins(configDir, n.appName()); err != nil {
+		fmt.Printf("⚠️ 恢复插件版本失败: %v\n", err)
+	}
+	return nil
+}
+
+func (nvim) Verify() error {
+	return verifyCommand("nvim")
+}
+
+// Install 安装 Neovim，Linux 下从源码编译，Windows 下通过 Scoop 安装，
+// 并将 LazyVim 的初始配置安装到 NVIM_APPNAME 对应的配置目录
+func (n nvim) Install() error {
 	fmt.Println("开始安装 Neovim...")
 
 	if runtime.GOOS == "windows" {
-		return installNvimOnWindows()
+		return installNvimOnWindows(n.appName())
 	}
 
 	// 1. 检查并安装依赖
@@ -70,16 +178,16 @@ func installNvim() error {
 	}
 
 	// 6. 安装配置管理器（可选）
-	if err := installNvimConfig(); err != nil {
+	if err := installNvimConfig(n.appName()); err != nil {
 		fmt.Printf("\n⚠️ 配置安装失败: %v\n", err)
 	}
 
 	fmt.Println("\n✅ Neovim 安装成功！")
-	printNvimUsage()
+	printNvimUsage(n.appName())
 	return nil
 }
 
-func installNvimOnWindows() error {
+func installNvimOnWindows(appName string) error {
 	// 检查是否安装了 scoop
 	if _, err := exec.LookPath("scoop"); err != nil {
 		fmt.Println("未检测到 Scoop，正在安装...")
@@ -108,26 +216,21 @@ func installNvimOnWindows() error {
 	}
 
 	// 安装配置管理器（可选）
-	if err := installNvimConfig(); err != nil {
+	if err := installNvimConfig(appName); err != nil {
 		fmt.Printf("\n⚠️ 配置安装失败: %v\n", err)
 	}
 
 	fmt.Println("\n✅ Neovim 安装成功！")
-	printNvimUsage()
+	printNvimUsage(appName)
 	return nil
 }
 
-func installNvimConfig() error {
+// installNvimConfig 将 LazyVim 的初始配置安装到 NVIM_APPNAME 为 appName 时的配置目录
+func installNvimConfig(appName string) error {
 	// 获取配置目录
-	configDir := ""
-	if runtime.GOOS == "windows" {
-		configDir = filepath.Join(os.Getenv("LOCALAPPDATA"), "nvim")
-	} else {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return fmt.Errorf("获取用户主目录失败: %v", err)
-		}
-		configDir = filepath.Join(homeDir, ".config", "nvim")
+	configDir, err := nvimConfigDir(appName)
+	if err != nil {
+		return err
 	}
 
 	// 备份现有配置
@@ -159,7 +262,7 @@ func installNvimConfig() error {
 	return nil
 }
 
-func printNvimUsage() {
+func printNvimUsage(appName string) {
 	fmt.Println("\n使用说明：")
 	fmt.Println("1. 在终端中输入 'nvim' 启动编辑器")
 	fmt.Println("\n2. 首次启动会自动安装插件，请耐心等待")
@@ -170,9 +273,7 @@ func printNvimUsage() {
 	fmt.Println("   - Space + fg: 全局搜索")
 	fmt.Println("   - Space + qq: 退出")
 	fmt.Println("\n4. 如果需要恢复原有配置，可以删除配置目录后还原备份")
-	if runtime.GOOS == "windows" {
-		fmt.Printf("   配置目录: %s\n", filepath.Join(os.Getenv("LOCALAPPDATA"), "nvim"))
-	} else {
-		fmt.Printf("   配置目录: ~/.config/nvim\n")
+	if configDir, err := nvimConfigDir(appName); err == nil {
+		fmt.Printf("   配置目录: %s\n", configDir)
 	}
 }
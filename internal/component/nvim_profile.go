@@ -0,0 +1,215 @@
+package component
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"qs-tools/internal/utils"
+	"qs-tools/internal/xdg"
+)
+
+// nvimProfileFile 状态目录中记录默认 Neovim 配置的文件
+const nvimProfileFile = "nvim-profile"
+
+// nvimAppNamePattern 支持的 NVIM_APPNAME，只允许配置目录下的一级目录
+var nvimAppNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// checkNvimAppName 检查配置名称是否可以作为 NVIM_APPNAME
+func checkNvimAppName(name string) error {
+	if !nvimAppNamePattern.MatchString(name) {
+		return fmt.Errorf("无效的 Neovim 配置名称: %s（只能包含字母、数字、\".\"、\"_\" 和 \"-\"）", name)
+	}
+	return nil
+}
+
+// nvimConfigBase 返回 Neovim 配置目录的上级目录，即 $XDG_CONFIG_HOME，
+// 未设置时 Windows 为 %LOCALAPPDATA%，其他系统为 ~/.config
+func nvimConfigBase() (string, error) {
+	configDir, err := utils.ComponentConfigDir(nvimDefaultAppName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(configDir), nil
+}
+
+// nvimConfigDir 返回 NVIM_APPNAME 为 appName 时 Neovim 的配置目录
+func nvimConfigDir(appName string) (string, error) {
+	base, err := nvimConfigBase()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, appName), nil
+}
+
+// nvimDataDir 返回 NVIM_APPNAME 为 appName 时 Neovim 的数据目录，与 stdpath('data') 相同：
+// $XDG_DATA_HOME/<appName>，未设置时为 ~/.local/share/<appName>，
+// Windows 上为 %LOCALAPPDATA%\<appName>-data
+func nvimDataDir(appName string) (string, error) {
+	base, err := xdg.DataHome()
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS == "windows" {
+		appName += "-data"
+	}
+	return filepath.Join(base, appName), nil
+}
+
+// Profile 返回配置对应的 NVIM_APPNAME
+func (n nvim) Profile() string {
+	return n.appName()
+}
+
+// Profiles 返回本地的 Neovim 配置，即配置目录下包含 init.lua 或 init.vim 的目录
+func (nvim) Profiles() ([]string, error) {
+	base, err := nvimConfigBase()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取配置目录失败: %v", err)
+	}
+
+	var profiles []string
+	for _, e := range entries {
+		if !e.IsDir() || checkNvimAppName(e.Name()) != nil {
+			continue
+		}
+		for _, init := range []string{"init.lua", "init.vim"} {
+			if _, err := os.Stat(filepath.Join(base, e.Name(), init)); err == nil {
+				profiles = append(profiles, e.Name())
+				break
+			}
+		}
+	}
+	sort.Strings(profiles)
+	return profiles, nil
+}
+
+// WithProfile 返回 NVIM_APPNAME 为 name 的配置，name 为空时使用默认的配置
+func (nvim) WithProfile(name string) (Component, error) {
+	if name == "" {
+		var err error
+		if name, err = (nvim{}).DefaultProfile(); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkNvimAppName(name); err != nil {
+		return nil, err
+	}
+	if name == nvimDefaultAppName {
+		return nvim{}, nil
+	}
+	return nvim{app: name}, nil
+}
+
+// DefaultProfile 返回通过 "qs-tools nvim switch" 设置的默认配置，没有设置时为 nvim
+func (nvim) DefaultProfile() (string, error) {
+	path, err := nvimProfilePath()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nvimDefaultAppName, nil
+		}
+		return "", fmt.Errorf("读取默认 Neovim 配置失败: %v", err)
+	}
+
+	name := strings.TrimSpace(string(data))
+	if err := checkNvimAppName(name); err != nil {
+		return "", fmt.Errorf("%s 中的默认配置无效: %v", path, err)
+	}
+	return name, nil
+}
+
+// SwitchProfile 将默认配置切换为本地已有的配置 name，同时设置用户环境中的 NVIM_APPNAME，
+// 使 nvim 默认使用该配置
+func (nvim) SwitchProfile(name string) error {
+	if err := checkNvimAppName(name); err != nil {
+		return err
+	}
+	configDir, err := nvimConfigDir(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(configDir); err != nil {
+		return fmt.Errorf("本地没有 Neovim 配置 %s: %s，可以先通过 \"qs-tools apply nvim %s\" 恢复", name, configDir, name)
+	}
+
+	path, err := nvimProfilePath()
+	if err != nil {
+		return err
+	}
+	if name == nvimDefaultAppName {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除默认 Neovim 配置记录失败: %v", err)
+		}
+	} else {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("创建状态目录失败: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(name+"\n"), 0644); err != nil {
+			return fmt.Errorf("保存默认 Neovim 配置失败: %v", err)
+		}
+	}
+
+	setNvimAppNameEnv(name)
+	return nil
+}
+
+// nvimProfilePath 返回记录默认 Neovim 配置的文件路径
+func nvimProfilePath() (string, error) {
+	stateDir, err := utils.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, nvimProfileFile), nil
+}
+
+// setNvimAppNameEnv 在用户环境中设置 NVIM_APPNAME，默认配置时删除该变量。
+// Windows 写入用户环境变量，有 fish 时设置为 fish 的全局变量，其他情况提示手动设置
+func setNvimAppNameEnv(name string) {
+	var cmd *exec.Cmd
+	switch {
+	case runtime.GOOS == "windows":
+		value := "'" + name + "'"
+		if name == nvimDefaultAppName {
+			value = "$null"
+		}
+		cmd = exec.Command("powershell", "-NoProfile", "-Command",
+			fmt.Sprintf("[Environment]::SetEnvironmentVariable('NVIM_APPNAME', %s, 'User')", value))
+	case verifyCommand("fish") == nil:
+		script := "set -Ux NVIM_APPNAME " + name
+		if name == nvimDefaultAppName {
+			script = "if set -qU NVIM_APPNAME; set -eU NVIM_APPNAME; end"
+		}
+		cmd = exec.Command("fish", "-c", script)
+	default:
+		if name == nvimDefaultAppName {
+			fmt.Println("请在 shell 配置中删除 NVIM_APPNAME 的设置")
+		} else {
+			fmt.Printf("请在 shell 配置中添加 export NVIM_APPNAME=%s\n", name)
+		}
+		return
+	}
+
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("⚠️ 设置环境变量 NVIM_APPNAME 失败: %v\n", err)
+		return
+	}
+	fmt.Println("已更新环境变量 NVIM_APPNAME，新打开的终端中生效")
+}
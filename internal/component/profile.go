@@ -0,0 +1,81 @@
+package component
+
+import (
+	"fmt"
+	"strings"
+
+	"qs-tools/internal/utils"
+)
+
+// HasProfiles 判断组件是否可以有多份配置
+func HasProfiles(c Component) bool {
+	_, ok := c.(profiler)
+	return ok
+}
+
+// asProfiler 返回组件的多配置接口，组件不支持多份配置时返回错误
+func asProfiler(c Component) (profiler, error) {
+	p, ok := c.(profiler)
+	if !ok {
+		return nil, fmt.Errorf("%s %w: 没有多份配置", c.Description(), ErrNotSupported)
+	}
+	return p, nil
+}
+
+// WithProfile 返回使用指定配置的组件，name 为空时使用默认的配置
+func WithProfile(c Component, name string) (Component, error) {
+	p, err := asProfiler(c)
+	if err != nil {
+		return nil, err
+	}
+	return p.WithProfile(name)
+}
+
+// Profiles 返回组件在本地已有的配置名称
+func Profiles(c Component) ([]string, error) {
+	p, err := asProfiler(c)
+	if err != nil {
+		return nil, err
+	}
+	return p.Profiles()
+}
+
+// RemoteProfiles 返回组件在远程服务器上有备份的配置名称
+func RemoteProfiles(c Component) ([]string, error) {
+	if _, err := asProfiler(c); err != nil {
+		return nil, err
+	}
+	names, err := utils.RemoteStore().ListComponents()
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []string
+	for _, name := range names {
+		if name != c.Name() && !strings.HasPrefix(name, c.Name()+ProfileSeparator) {
+			continue
+		}
+		if pc, ok := Get(name); ok {
+			profiles = append(profiles, pc.(profiler).Profile())
+		}
+	}
+	return profiles, nil
+}
+
+// DefaultProfile 返回组件默认的配置名称
+func DefaultProfile(c Component) (string, error) {
+	p, err := asProfiler(c)
+	if err != nil {
+		return "", err
+	}
+	return p.DefaultProfile()
+}
+
+// SwitchProfile 将组件默认的配置切换为 name
+func SwitchProfile(c Component, name string) error {
+	p, err := asProfiler(c)
+	if err != nil {
+		return err
+	}
+	return p.SwitchProfile(name)
+}
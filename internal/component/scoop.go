@@ -0,0 +1,290 @@
+package component
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"qs-tools/internal/config"
+	"qs-tools/internal/manifest"
+	"qs-tools/internal/utils"
+)
+
+func init() {
+	Register(scoop{})
+}
+
+// scoop Scoop 包管理器组件，备份的是 scoop export 导出的已安装应用和软件源列表
+type scoop struct{}
+
+func (scoop) Name() string        { return "scoop" }
+func (scoop) Description() string { return "Scoop 包管理器" }
+func (scoop) Platforms() []string { return []string{"windows"} }
+
+// ConfigPaths Scoop 的配置来自 scoop 命令的导出结果，没有需要直接备份的本地路径
+func (scoop) ConfigPaths() ([]string, error) {
+	return nil, nil
+}
+
+func (s scoop) Backup(opts utils.BackupOptions) (*utils.BackupResult, error) {
+	// 创建临时目录
+	exportDir, cleanup, err := utils.CreateTempDir("scoop-backup")
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	if err := s.export(exportDir); err != nil {
+		return nil, err
+	}
+	opts.Virtual = true
+	return backupTargets(s, manifest.Targets{{Path: exportDir}}, utils.ToolVersion("scoop", "--version"), opts)
+}
+
+// export 将 scoop export 导出的应用和软件源列表保存到 exportDir 中的 scoopfile.json
+func (scoop) export(exportDir string) error {
+	fmt.Println("导出已安装的应用和软件源列表...")
+	export, err := exportScoop()
+	if err != nil {
+		return err
+	}
+	data, err := formatScoopExport(export)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(exportDir, scoopFile), data, 0644); err != nil {
+		return fmt.Errorf("写入 %s 失败: %v", scoopFile, err)
+	}
+	return nil
+}
+
+// exportScoop 返回当前已安装的应用和软件源
+func exportScoop() (*scoopExport, error) {
+	output, err := exec.Command("scoop", "export").Output()
+	if err != nil {
+		return nil, fmt.Errorf("导出应用列表失败: %v", err)
+	}
+	return parseScoopExport(output)
+}
+
+// Apply 按备份中的 scoopfile.json 添加缺少的软件源并安装缺少的应用，已安装的应用保持不变
+func (s scoop) Apply(opts utils.ApplyOptions) error {
+	// 创建临时目录
+	restoreDir, cleanup, err := utils.CreateTempDir("scoop-restore")
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	targets := manifest.Targets{{Path: restoreDir}}
+
+	pin, err := scoopPinVersions()
+	if err != nil {
+		return err
+	}
+
+	// 预览时与当前导出的应用和软件源列表比较，列出要添加的软件源和要安装的应用
+	if opts.DryRun {
+		if err := s.export(restoreDir); err != nil {
+			return err
+		}
+		return previewTargets(s, targets, opts, func(changes []utils.FileChange) ([]utils.FileChange, error) {
+			return previewScoopPlan(changes, pin)
+		})
+	}
+
+	// 从远程服务器下载并解压到临时目录，恢复到其他目录时只解压不安装
+	opts.Virtual = true
+	if err := applyTargets(s, targets, opts); err != nil {
+		return err
+	}
+	if opts.Target != "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(restoreDir, scoopFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("备份中没有 %s，旧版本的备份无法恢复，请在原主机上重新备份", scoopFile)
+		}
+		return fmt.Errorf("读取 %s 失败: %v", scoopFile, err)
+	}
+	backup, err := parseScoopExport(data)
+	if err != nil {
+		return err
+	}
+	installed, err := exportScoop()
+	if err != nil {
+		return err
+	}
+
+	plan := planScoopRestore(backup, installed, pin)
+	fmt.Println()
+	printScoopPlan(plan)
+	return runScoopPlan(plan)
+}
+
+// scoopPinVersions 返回配置文件中是否要求恢复时锁定版本
+func scoopPinVersions() (bool, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return false, err
+	}
+	return cfg.Component("scoop").PinVersions, nil
+}
+
+// previewScoopPlan 预览时打印恢复计划，scoopfile.json 本身的差异不再显示
+func previewScoopPlan(changes []utils.FileChange, pin bool) ([]utils.FileChange, error) {
+	var rest []utils.FileChange
+	for _, c := range changes {
+		if c.Path != scoopFile {
+			rest = append(rest, c)
+			continue
+		}
+		if c.Kind == utils.ChangeDeleted {
+			continue
+		}
+		backup, err := parseScoopExport(c.Remote)
+		if err != nil {
+			return nil, err
+		}
+		installed := &scoopExport{}
+		if c.Kind != utils.ChangeAdded {
+			if installed, err = parseScoopExport(c.Local); err != nil {
+				return nil, err
+			}
+		}
+		fmt.Println()
+		printScoopPlan(planScoopRestore(backup, installed, pin))
+	}
+	return rest, nil
+}
+
+// runScoopPlan 按计划添加软件源和安装应用，单个软件源或应用失败时继续，
+// 最后返回失败数量对应的错误
+func runScoopPlan(plan scoopPlan) error {
+	var failed []string
+	for _, b := range plan.Buckets {
+		args := []string{"bucket", "add", b.Name}
+		if b.Source != "" {
+			args = append(args, b.Source)
+		}
+		if err := runScoop(args...); err != nil {
+			fmt.Printf("❌ 添加软件源 %s 失败: %v\n", b.Name, err)
+			failed = append(failed, b.Name)
+		}
+	}
+
+	for _, install := range plan.Installs {
+		args := []string{"install", install.Arg}
+		if install.App.global() {
+			args = append(args, "--global")
+		}
+		if err := runScoop(args...); err != nil {
+			fmt.Printf("❌ 安装 %s 失败: %v\n", install.Arg, err)
+			failed = append(failed, install.App.Name)
+			continue
+		}
+		if install.Hold {
+			args := []string{"hold", install.App.Name}
+			if install.App.global() {
+				args = append(args, "--global")
+			}
+			if err := runScoop(args...); err != nil {
+				fmt.Printf("⚠️ 锁定 %s 的版本失败: %v\n", install.App.Name, err)
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d 个软件源或应用恢复失败: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// runScoop 执行 scoop 命令，输出直接显示在终端
+func runScoop(args ...string) error {
+	fmt.Printf("\n> scoop %s\n", strings.Join(args, " "))
+	cmd := exec.Command("scoop", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (scoop) Verify() error {
+	return verifyCommand("scoop")
+}
+
+// Install 安装 Scoop 包管理器并添加常用软件源
+func (scoop) Install() error {
+	fmt.Println("开始安装 Scoop 包管理器...")
+
+	// 检查是否已安装
+	if _, err := exec.LookPath("scoop"); err == nil {
+		fmt.Println("\nScoop 已经安装。")
+		printScoopUsage()
+		return nil
+	}
+
+	// 获取用户主目录
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("获取用户主目录失败: %v", err)
+	}
+
+	// 设置 SCOOP 环境变量
+	scoopDir := filepath.Join(homeDir, "scoop")
+	os.Setenv("SCOOP", scoopDir)
+
+	// 使用 PowerShell 安装 Scoop
+	fmt.Println("\n1. 下载并安装 Scoop...")
+	installCmd := exec.Command("powershell", "-Command",
+		`Set-ExecutionPolicy RemoteSigned -Scope CurrentUser -Force; [System.Net.ServicePointManager]::SecurityProtocol = [System.Net.ServicePointManager]::SecurityProtocol -bor 3072; iex ((New-Object System.Net.WebClient).DownloadString('https://get.scoop.sh'))`)
+	installCmd.Stdout = os.Stdout
+	installCmd.Stderr = os.Stderr
+	if err := installCmd.Run(); err != nil {
+		return fmt.Errorf("安装 Scoop 失败: %v", err)
+	}
+
+	// 添加常用 bucket
+	fmt.Println("\n2. 添加常用软件源...")
+	buckets := []string{"extras", "versions", "nerd-fonts", "java"}
+	for _, bucket := range buckets {
+		fmt.Printf("添加 %s bucket...\n", bucket)
+		addCmd := exec.Command("scoop", "bucket", "add", bucket)
+		addCmd.Stdout = os.Stdout
+		addCmd.Stderr = os.Stderr
+		addCmd.Run() // 忽略错误，因为可能已经添加
+	}
+
+	fmt.Println("\n✅ Scoop 安装成功！")
+	printScoopUsage()
+	return nil
+}
+
+func printScoopUsage() {
+	fmt.Println("\n使用说明：")
+	fmt.Println("1. 基本命令：")
+	fmt.Println("   - 搜索软件：scoop search <app>")
+	fmt.Println("   - 安装软件：scoop install <app>")
+	fmt.Println("   - 更新软件：scoop update <app>")
+	fmt.Println("   - 卸载软件：scoop uninstall <app>")
+	fmt.Println("   - 查看已安装：scoop list")
+	fmt.Println("   - 清理缓存：scoop cleanup")
+	fmt.Println("\n2. 软件源管理：")
+	fmt.Println("   - 添加源：scoop bucket add <bucket>")
+	fmt.Println("   - 移除源：scoop bucket rm <bucket>")
+	fmt.Println("   - 查看已添加源：scoop bucket list")
+	fmt.Println("\n3. 系统维护：")
+	fmt.Println("   - 更新 Scoop：scoop update")
+	fmt.Println("   - 更新所有软件：scoop update *")
+	fmt.Println("   - 检查问题：scoop checkup")
+	fmt.Println("\n4. 已添加的软件源：")
+	fmt.Println("   - extras: 包含大量常用软件")
+	fmt.Println("   - versions: 包含软件的多个版本")
+	fmt.Println("   - nerd-fonts: 包含编程字体")
+	fmt.Println("   - java: 包含 Java 相关软件")
+	fmt.Println("\n5. 推荐安装的基础软件：")
+	fmt.Println("   scoop install git 7zip sudo curl")
+} 
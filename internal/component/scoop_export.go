@@ -0,0 +1,215 @@
+package component
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// scoopFile 备份中保存 scoop export 结果的文件，可以直接用于 scoop import
+const scoopFile = "scoopfile.json"
+
+// scoopExport scoop export 输出的 JSON，只保留恢复需要的字段，
+// 去掉更新时间等每次导出都会变化的字段
+type scoopExport struct {
+	Buckets []scoopBucket `json:"buckets"`
+	Apps    []scoopApp    `json:"apps"`
+}
+
+// scoopBucket 软件源
+type scoopBucket struct {
+	Name string `json:"Name"`
+	// Source 软件源的仓库地址
+	Source string `json:"Source"`
+}
+
+// scoopApp 已安装的应用
+type scoopApp struct {
+	Name    string `json:"Name"`
+	Version string `json:"Version"`
+	// Source 应用所在的软件源，通过清单地址或文件安装的应用为该地址
+	Source string `json:"Source"`
+	// Info 安装信息，如 "Global install"、"Held package"，多项以逗号分隔
+	Info string `json:"Info,omitempty"`
+}
+
+// global 是否为全局安装（scoop install -g）
+func (a scoopApp) global() bool {
+	return strings.Contains(a.Info, "Global install")
+}
+
+// held 是否锁定了版本（scoop hold）
+func (a scoopApp) held() bool {
+	return strings.Contains(a.Info, "Held package")
+}
+
+// fromBucket 应用是否来自软件源，而不是通过清单地址或文件安装
+func (a scoopApp) fromBucket() bool {
+	return a.Source != "" && !strings.ContainsAny(a.Source, `/\:`)
+}
+
+// parseScoopExport 解析 scoop export 的输出。旧版本的 scoop export 输出的是文本，
+// 此时返回错误提示升级 Scoop
+func parseScoopExport(data []byte) (*scoopExport, error) {
+	// PowerShell 输出的文件可能带有 BOM
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return nil, fmt.Errorf("无法识别 scoop export 的输出，请通过 \"scoop update\" 升级 Scoop")
+	}
+
+	var export scoopExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("解析 scoop export 的输出失败: %v", err)
+	}
+	for _, b := range export.Buckets {
+		if b.Name == "" {
+			return nil, fmt.Errorf("解析 scoop export 的输出失败: 软件源缺少名称")
+		}
+	}
+	for _, a := range export.Apps {
+		if a.Name == "" {
+			return nil, fmt.Errorf("解析 scoop export 的输出失败: 应用缺少名称")
+		}
+	}
+	return &export, nil
+}
+
+// formatScoopExport 按软件源和应用名称排序后生成 scoopfile.json 的内容，
+// 使相同的应用和软件源总是得到相同的文件
+func formatScoopExport(export *scoopExport) ([]byte, error) {
+	sorted := scoopExport{
+		Buckets: append([]scoopBucket{}, export.Buckets...),
+		Apps:    append([]scoopApp{}, export.Apps...),
+	}
+	sort.Slice(sorted.Buckets, func(i, j int) bool {
+		return strings.ToLower(sorted.Buckets[i].Name) < strings.ToLower(sorted.Buckets[j].Name)
+	})
+	sort.Slice(sorted.Apps, func(i, j int) bool {
+		return strings.ToLower(sorted.Apps[i].Name) < strings.ToLower(sorted.Apps[j].Name)
+	})
+
+	data, err := json.MarshalIndent(sorted, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("生成 %s 失败: %v", scoopFile, err)
+	}
+	return append(data, '\n'), nil
+}
+
+// scoopInstall 恢复时要安装的一个应用
+type scoopInstall struct {
+	App scoopApp
+	// Arg 传给 scoop install 的参数，如 extras/vscode 或 extras/vscode@1.80.0
+	Arg string
+	// Hold 安装后是否锁定版本
+	Hold bool
+}
+
+// scoopPlan 恢复 Scoop 应用和软件源的计划
+type scoopPlan struct {
+	// Buckets 需要添加的软件源，Source 为空时是 Scoop 内置的软件源
+	Buckets []scoopBucket
+	// Installs 需要安装的应用
+	Installs []scoopInstall
+	// Skipped 已经安装的应用
+	Skipped []scoopApp
+}
+
+// planScoopRestore 比较备份和当前已安装的应用，生成恢复计划：
+// 先添加缺少的软件源（包括应用所在但备份中没有记录的软件源），再安装缺少的应用，
+// 已安装的应用跳过。pin 为 true 时安装备份中的版本并锁定，
+// 备份中锁定了版本的应用恢复后同样锁定
+func planScoopRestore(backup, installed *scoopExport, pin bool) scoopPlan {
+	var plan scoopPlan
+
+	hasBucket := make(map[string]bool)
+	for _, b := range installed.Buckets {
+		hasBucket[strings.ToLower(b.Name)] = true
+	}
+	addBucket := func(b scoopBucket) {
+		if key := strings.ToLower(b.Name); !hasBucket[key] {
+			hasBucket[key] = true
+			plan.Buckets = append(plan.Buckets, b)
+		}
+	}
+
+	hasApp := make(map[string]bool)
+	for _, a := range installed.Apps {
+		hasApp[strings.ToLower(a.Name)] = true
+	}
+
+	var apps []scoopInstall
+	for _, a := range backup.Apps {
+		if hasApp[strings.ToLower(a.Name)] {
+			plan.Skipped = append(plan.Skipped, a)
+			continue
+		}
+		hasApp[strings.ToLower(a.Name)] = true
+
+		install := scoopInstall{App: a, Arg: a.Name, Hold: a.held()}
+		switch {
+		case a.fromBucket():
+			install.Arg = a.Source + "/" + a.Name
+			if pin && a.Version != "" {
+				install.Arg += "@" + a.Version
+				install.Hold = true
+			}
+		case a.Source != "":
+			install.Arg = a.Source
+		}
+		apps = append(apps, install)
+	}
+
+	// 备份中记录了地址的软件源优先，应用所在的其他软件源按名称添加
+	for _, b := range backup.Buckets {
+		addBucket(b)
+	}
+	for _, install := range apps {
+		if install.App.fromBucket() {
+			addBucket(scoopBucket{Name: install.App.Source})
+		}
+	}
+	plan.Installs = apps
+	return plan
+}
+
+// printScoopPlan 打印恢复计划
+func printScoopPlan(plan scoopPlan) {
+	if len(plan.Buckets) == 0 && len(plan.Installs) == 0 {
+		fmt.Printf("备份中的 %d 个应用都已安装\n", len(plan.Skipped))
+		return
+	}
+
+	if len(plan.Buckets) > 0 {
+		fmt.Println("添加软件源：")
+		for _, b := range plan.Buckets {
+			if b.Source != "" {
+				fmt.Printf("  + %s (%s)\n", b.Name, b.Source)
+			} else {
+				fmt.Printf("  + %s\n", b.Name)
+			}
+		}
+	}
+	if len(plan.Installs) > 0 {
+		fmt.Println("安装应用：")
+		for _, install := range plan.Installs {
+			var notes []string
+			if install.App.global() {
+				notes = append(notes, "全局")
+			}
+			if install.Hold {
+				notes = append(notes, "锁定版本")
+			}
+			if len(notes) > 0 {
+				fmt.Printf("  + %s（%s）\n", install.Arg, strings.Join(notes, "，"))
+			} else {
+				fmt.Printf("  + %s\n", install.Arg)
+			}
+		}
+	}
+	if len(plan.Skipped) > 0 {
+		fmt.Printf("跳过 %d 个已安装的应用\n", len(plan.Skipped))
+	}
+}
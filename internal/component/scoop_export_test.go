@@ -0,0 +1,200 @@
+package component
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// readScoopExport 读取 testdata 中的 scoop export 输出并解析
+func readScoopExport(t *testing.T, name string) *scoopExport {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	export, err := parseScoopExport(data)
+	if err != nil {
+		t.Fatalf("解析 %s 失败: %v", name, err)
+	}
+	return export
+}
+
+func TestParseScoopExport(t *testing.T) {
+	// PowerShell 重定向输出的文件带有 BOM 和 CRLF 换行
+	export := readScoopExport(t, "scoop-export-bom.json")
+	if len(export.Buckets) != 3 || len(export.Apps) != 7 {
+		t.Fatalf("软件源 %d 个、应用 %d 个，应为 3 个和 7 个", len(export.Buckets), len(export.Apps))
+	}
+
+	apps := make(map[string]scoopApp)
+	for _, a := range export.Apps {
+		apps[a.Name] = a
+	}
+	tests := []struct {
+		name       string
+		fromBucket bool
+		held       bool
+		global     bool
+	}{
+		{name: "git", fromBucket: true},
+		{name: "vscode", fromBucket: true, held: true},
+		{name: "7zip", fromBucket: true, global: true},
+		{name: "tool"},
+		{name: "local-app"},
+	}
+	for _, tt := range tests {
+		a, ok := apps[tt.name]
+		if !ok {
+			t.Fatalf("缺少应用 %s", tt.name)
+		}
+		if a.fromBucket() != tt.fromBucket || a.held() != tt.held || a.global() != tt.global {
+			t.Errorf("%s: fromBucket=%v held=%v global=%v，应为 %v %v %v", tt.name,
+				a.fromBucket(), a.held(), a.global(), tt.fromBucket, tt.held, tt.global)
+		}
+	}
+}
+
+func TestParseScoopExportInvalid(t *testing.T) {
+	// 旧版本的 scoop export 输出的是文本
+	text, err := os.ReadFile(filepath.Join("testdata", "scoop-export-text.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "旧版本的文本输出", data: text, want: "scoop update"},
+		{name: "空输出", data: []byte("\xef\xbb\xbf\r\n"), want: "scoop update"},
+		{name: "应用缺少名称", data: []byte(`{"buckets": [], "apps": [{"Source": "main"}]}`), want: "应用缺少名称"},
+		{name: "软件源缺少名称", data: []byte(`{"buckets": [{"Source": "https://example.com"}], "apps": []}`), want: "软件源缺少名称"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseScoopExport(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("错误为 %v，应包含 %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatScoopExport(t *testing.T) {
+	export := readScoopExport(t, "scoop-export-bom.json")
+	data, err := formatScoopExport(export)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "Updated") || strings.Contains(string(data), "Manifests") {
+		t.Fatalf("应去掉每次导出都会变化的字段:\n%s", data)
+	}
+
+	// 重新解析后内容相同，顺序与原始顺序无关
+	parsed, err := parseScoopExport(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Apps[0].Name != "7zip" || parsed.Buckets[0].Name != "Extras" {
+		t.Fatalf("应按名称排序，第一个应用为 %s，第一个软件源为 %s", parsed.Apps[0].Name, parsed.Buckets[0].Name)
+	}
+	again, err := formatScoopExport(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(data) {
+		t.Fatalf("两次生成的内容不同:\n%s\n%s", data, again)
+	}
+}
+
+func TestPlanScoopRestore(t *testing.T) {
+	backup := readScoopExport(t, "scoop-export-bom.json")
+	installed := readScoopExport(t, "scoop-installed.json")
+
+	tests := []struct {
+		name     string
+		pin      bool
+		buckets  []string
+		installs []string
+		holds    []string
+	}{
+		{
+			name: "不锁定版本",
+			// main 和 extras 已添加（名称不区分大小写），nerd-fonts 只在应用中出现，按名称添加
+			buckets: []string{"my-bucket", "nerd-fonts"},
+			installs: []string{
+				"extras/vscode",
+				"main/7zip",
+				"nerd-fonts/JetBrainsMono-NF",
+				"https://raw.githubusercontent.com/example/manifests/main/tool.json",
+				`C:\Users\dev\manifests\local-app.json`,
+			},
+			holds: []string{"vscode"},
+		},
+		{
+			name:    "锁定版本",
+			pin:     true,
+			buckets: []string{"my-bucket", "nerd-fonts"},
+			installs: []string{
+				"extras/vscode@1.80.0",
+				"main/7zip@24.08",
+				"nerd-fonts/JetBrainsMono-NF@3.2.1",
+				// 通过清单地址或文件安装的应用没有版本可选
+				"https://raw.githubusercontent.com/example/manifests/main/tool.json",
+				`C:\Users\dev\manifests\local-app.json`,
+			},
+			holds: []string{"vscode", "7zip", "JetBrainsMono-NF"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planScoopRestore(backup, installed, tt.pin)
+
+			var buckets []string
+			for _, b := range plan.Buckets {
+				buckets = append(buckets, b.Name)
+			}
+			if !reflect.DeepEqual(buckets, tt.buckets) {
+				t.Errorf("添加的软件源为 %v，应为 %v", buckets, tt.buckets)
+			}
+			if plan.Buckets[0].Source != "https://github.com/example/my-bucket" {
+				t.Errorf("备份中的软件源应使用记录的地址，实际为 %q", plan.Buckets[0].Source)
+			}
+
+			var installs, holds []string
+			for _, install := range plan.Installs {
+				installs = append(installs, install.Arg)
+				if install.Hold {
+					holds = append(holds, install.App.Name)
+				}
+			}
+			if !reflect.DeepEqual(installs, tt.installs) {
+				t.Errorf("安装的应用为 %v，应为 %v", installs, tt.installs)
+			}
+			if !reflect.DeepEqual(holds, tt.holds) {
+				t.Errorf("锁定版本的应用为 %v，应为 %v", holds, tt.holds)
+			}
+
+			// 已安装的应用跳过，名称不区分大小写
+			var skipped []string
+			for _, a := range plan.Skipped {
+				skipped = append(skipped, a.Name)
+			}
+			if want := []string{"git", "fd"}; !reflect.DeepEqual(skipped, want) {
+				t.Errorf("跳过的应用为 %v，应为 %v", skipped, want)
+			}
+		})
+	}
+}
+
+func TestPlanScoopRestoreAllInstalled(t *testing.T) {
+	installed := readScoopExport(t, "scoop-installed.json")
+	plan := planScoopRestore(installed, installed, true)
+	if len(plan.Buckets) != 0 || len(plan.Installs) != 0 || len(plan.Skipped) != len(installed.Apps) {
+		t.Fatalf("与当前相同的备份不应有需要恢复的内容: %+v", plan)
+	}
+}
@@ -0,0 +1,83 @@
+package component
+
+import (
+	"os"
+
+	"qs-tools/internal/utils"
+)
+
+// Status 组件在当前系统上的状态
+type Status struct {
+	Component Component
+	// Supported 组件是否支持当前系统
+	Supported bool
+	// Installed 组件是否已安装
+	Installed bool
+	// HasConfig 本地是否有组件配置，配置不直接来自本地文件的组件为 nil
+	HasConfig *bool
+	// Latest 远程最新的备份，没有备份时为 nil
+	Latest *utils.BackupMeta
+	// State 本地配置与远程最新备份的同步状态，无法比较时为空
+	State utils.SyncState
+	// Err 读取远程备份或比较时的错误
+	Err error
+}
+
+// GetStatus 检查组件的安装情况、本地配置和与远程最新备份的差异
+func GetStatus(c Component) Status {
+	s := Status{Component: c, Supported: CheckPlatform(c) == nil}
+	if !s.Supported {
+		return s
+	}
+	s.Installed = c.Verify() == nil
+
+	tp, ok := c.(targetsProvider)
+	if !ok {
+		// 配置来自命令导出结果的组件只能查看最新的备份
+		versions, err := utils.RemoteStore().List(c.Name())
+		if err != nil {
+			s.Err = err
+		} else if len(versions) > 0 {
+			s.Latest = &versions[len(versions)-1]
+		}
+		return s
+	}
+
+	targets, err := tp.targets()
+	if err != nil {
+		s.Err = err
+		return s
+	}
+	hasConfig := false
+	for _, t := range targets {
+		if _, err := os.Stat(t.Path); err == nil {
+			hasConfig = true
+			break
+		}
+	}
+	s.HasConfig = &hasConfig
+
+	s.Latest, s.State, s.Err = utils.CompareWithRemote(c.Name(), targets)
+	return s
+}
+
+// HasLocalConfig 判断组件是否支持当前系统并且有可以备份的本地配置，
+// 配置不直接来自本地文件的组件以是否已安装为准
+func HasLocalConfig(c Component) bool {
+	if CheckPlatform(c) != nil {
+		return false
+	}
+	paths, err := c.ConfigPaths()
+	if err != nil {
+		return false
+	}
+	if len(paths) == 0 {
+		return c.Verify() == nil
+	}
+	for _, p := range paths {
+		if _, err := os.Stat(p); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package component
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"qs-tools/internal/manifest"
+	"qs-tools/internal/utils"
+)
+
+// backupTargets 按备份目标备份组件配置，适用于配置由文件和目录组成的组件
+func backupTargets(c Component, targets manifest.Targets, toolVersion string, opts utils.BackupOptions) (*utils.BackupResult, error) {
+	// 至少要有一个备份目标存在
+	exists := false
+	for _, t := range targets {
+		if _, err := os.Stat(t.Path); err == nil {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s 配置不存在: %s", c.Description(), strings.Join(targets.Paths(), ", "))
+	}
+
+	// 生成备份清单
+	m, err := manifest.Build(c.Name(), targets, toolVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	// 打包压缩并上传到远程服务器
+	return utils.BackupTargets(targets, m, opts)
+}
+
+// applyTargets 从远程服务器下载备份，按备份目标恢复组件配置，
+// 预览时只打印恢复会带来的文件变化，指定了其他目录时恢复到该目录
+func applyTargets(c Component, targets manifest.Targets, opts utils.ApplyOptions) error {
+	if opts.DryRun {
+		return previewTargets(c, targets, opts, nil)
+	}
+	if opts.Target != "" {
+		targets = targets.Relocate(opts.Target)
+	}
+
+	// 恢复前保存本地配置的快照，可以通过 rollback 命令回滚，临时目录没有需要保存的内容
+	if opts.Target == "" && !opts.Virtual {
+		snapshot, err := utils.CreateSnapshot(c.Name(), targets)
+		if err != nil {
+			return err
+		}
+		if snapshot.Files == 0 {
+			fmt.Printf("本地没有配置，已保存空快照 %s，通过 \"qs-tools rollback %s\" 回滚时会删除恢复的文件\n", snapshot.ID, c.Name())
+		} else {
+			fmt.Printf("已保存本地配置快照 %s，可以通过 \"qs-tools rollback %s\" 回滚\n", snapshot.ID, c.Name())
+		}
+	}
+
+	meta, err := utils.RestoreTargets(c.Name(), targets, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已恢复备份版本: %s\n", describeVersion(meta))
+	return nil
+}
+
+// previewTargets 打印恢复会带来的文件变化，adjust 不为 nil 时由其调整变化，
+// 用于恢复时不直接覆盖而是合并的文件。恢复到其他目录时与该目录中的文件比较
+func previewTargets(c Component, targets manifest.Targets, opts utils.ApplyOptions, adjust func([]utils.FileChange) ([]utils.FileChange, error)) error {
+	if opts.Target != "" {
+		targets = targets.Relocate(opts.Target)
+	}
+	meta, changes, err := utils.PreviewRestore(c.Name(), targets, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("备份版本: %s\n", describeVersion(meta))
+	if adjust != nil {
+		if changes, err = adjust(changes); err != nil {
+			return err
+		}
+	}
+	utils.PrintChanges(changes)
+	return nil
+}
+
+// describeVersion 返回备份版本的说明，包括版本号、标签、来源主机和备份时间
+func describeVersion(meta *utils.BackupMeta) string {
+	desc := meta.ID
+	if len(meta.Tags) > 0 {
+		desc += " [" + strings.Join(meta.Tags, ", ") + "]"
+	}
+	return fmt.Sprintf("%s（来自 %s，备份于 %s）", desc, meta.Hostname,
+		meta.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+}
+
+// targetsProvider 配置由文件和目录组成的组件实现该接口
+type targetsProvider interface {
+	targets() (manifest.Targets, error)
+}
+
+// Snapshots 返回组件在本地保存的恢复前快照，按创建时间从旧到新排序
+func Snapshots(c Component) ([]utils.Snapshot, error) {
+	if _, ok := c.(targetsProvider); !ok {
+		return nil, fmt.Errorf("%s %w: 没有本地快照", c.Description(), ErrNotSupported)
+	}
+	return utils.ListSnapshots(c.Name())
+}
+
+// Rollback 将组件配置回滚到恢复前保存的快照，id 为空时回滚到最新的快照
+func Rollback(c Component, id string) (*utils.Snapshot, error) {
+	tp, ok := c.(targetsProvider)
+	if !ok {
+		return nil, fmt.Errorf("%s %w: 没有本地快照", c.Description(), ErrNotSupported)
+	}
+	targets, err := tp.targets()
+	if err != nil {
+		return nil, err
+	}
+	return utils.RestoreSnapshot(c.Name(), id, targets)
+}
@@ -1,11 +1,13 @@
-package install
+package component
 
 import (
 	"archive/tar"
 	"compress/gzip"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 )
@@ -101,3 +103,11 @@ func extractTarGz(archivePath, destPath string) error {
 
 	return nil
 } 
+
+// verifyCommand 检查命令是否已安装并可以在 PATH 中找到
+func verifyCommand(name string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("未找到 %s 命令", name)
+	}
+	return nil
+}
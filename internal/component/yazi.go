@@ -1,4 +1,4 @@
-package install
+package component
 
 import (
 	"fmt"
@@ -6,9 +6,62 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+
+	"qs-tools/internal/manifest"
+	"qs-tools/internal/utils"
 )
 
-func installYazi() error {
+func init() {
+	Register(yazi{})
+}
+
+// yazi Yazi 文件管理器组件
+type yazi struct{}
+
+func (yazi) Name() string        { return "yazi" }
+func (yazi) Description() string { return "Yazi 文件管理器" }
+func (yazi) Platforms() []string { return nil }
+
+func (y yazi) ConfigPaths() ([]string, error) {
+	targets, err := y.targets()
+	if err != nil {
+		return nil, err
+	}
+	return targets.Paths(), nil
+}
+
+// targets 备份 Yazi 配置目录，设置了 YAZI_CONFIG_HOME 时为该目录，
+// 否则 Windows 为 %APPDATA%\yazi\config，其他系统为 $XDG_CONFIG_HOME/yazi（默认为 ~/.config/yazi）
+func (yazi) targets() (manifest.Targets, error) {
+	configDir, err := utils.ComponentConfigDir("yazi")
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Targets{{Path: configDir}}, nil
+}
+
+func (y yazi) Backup(opts utils.BackupOptions) (*utils.BackupResult, error) {
+	targets, err := y.targets()
+	if err != nil {
+		return nil, err
+	}
+	return backupTargets(y, targets, utils.ToolVersion("yazi", "--version"), opts)
+}
+
+func (y yazi) Apply(opts utils.ApplyOptions) error {
+	targets, err := y.targets()
+	if err != nil {
+		return err
+	}
+	return applyTargets(y, targets, opts)
+}
+
+func (yazi) Verify() error {
+	return verifyCommand("yazi")
+}
+
+// Install 安装 Yazi，Linux 下从源码编译，Windows 下通过 Scoop 安装
+func (yazi) Install() error {
 	fmt.Println("开始安装 Yazi 文件管理器...")
 
 	// 1. 检查系统类型
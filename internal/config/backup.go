@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+// 备份配置
+var (
+	// BackupPassphraseEnv 备份加密口令所在的环境变量，设置后备份会加密后再上传
+	BackupPassphraseEnv = "QS_TOOLS_PASSPHRASE"
+	// MaxIncrementalBackups 两次全量备份之间最多连续进行的增量备份次数，超过后自动进行全量备份
+	MaxIncrementalBackups = 6
+	// MaxSnapshots 每个组件在本地保留的恢复前快照数量，超过后删除最旧的快照
+	MaxSnapshots = 5
+)
+
+// 自动备份配置
+var (
+	// WatchDebounce 监视模式下配置最后一次修改后等待的时间，期间的连续修改只触发一次备份
+	WatchDebounce = 10 * time.Second
+	// WatchRetryInterval 监视模式下无法连接远程服务器时首次重试的间隔，之后每次加倍
+	WatchRetryInterval = 30 * time.Second
+	// WatchMaxRetryInterval 监视模式下重试间隔的上限
+	WatchMaxRetryInterval = 30 * time.Minute
+)
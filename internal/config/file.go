@@ -0,0 +1,188 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"qs-tools/internal/xdg"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv 指定配置文件路径的环境变量，未设置时使用 $XDG_CONFIG_HOME/qs-tools/config.yaml，
+// 默认为 ~/.config/qs-tools/config.yaml（Windows 为 %APPDATA%\qs-tools\config.yaml）
+var ConfigFileEnv = "QS_TOOLS_CONFIG"
+
+// Config 配置文件内容
+type Config struct {
+	// Components 组件配置，键为组件名称。配置了 Paths 的是用户自定义的组件，
+	// 与内置组件同名且没有配置 Paths 的是内置组件的设置
+	Components map[string]ComponentConfig `yaml:"components"`
+	// Vars 渲染模板文件时可以使用的变量
+	Vars map[string]string `yaml:"vars"`
+	// Secrets 备份时发现疑似密钥的处理策略，为空时取消备份
+	Secrets string `yaml:"secrets"`
+}
+
+// ComponentConfig 组件配置。用户自定义的组件由一组文件和目录组成，只支持备份和恢复
+type ComponentConfig struct {
+	// Description 组件的显示名称，为空时使用组件名称
+	Description string `yaml:"description"`
+	// Paths 组件包含的文件和目录
+	Paths []PathConfig `yaml:"paths"`
+	// Conflict 恢复时本地有修改的文件的处理策略，为空时覆盖本地文件
+	Conflict string `yaml:"conflict"`
+	// Vars 渲染该组件的模板文件时使用的变量，覆盖全局的同名变量
+	Vars map[string]string `yaml:"vars"`
+	// Secrets 备份该组件时发现疑似密钥的处理策略，为空时使用全局设置
+	Secrets string `yaml:"secrets"`
+	// Hooks 操作前后执行的 shell 命令，键为阶段名称，如 pre-backup、post-apply
+	Hooks map[string]string `yaml:"hooks"`
+	// Variables 恢复 fish 全局变量时的合并规则，键为变量名（支持 glob），
+	// 值为 remote-wins、local-wins 或 excluded，只用于 fish 组件
+	Variables map[string]string `yaml:"variables"`
+	// PinVersions 恢复 Scoop 应用时安装备份中的版本并锁定（scoop hold），只用于 scoop 组件
+	PinVersions bool `yaml:"pin_versions"`
+}
+
+// PathConfig 自定义组件中的一个文件或目录
+type PathConfig struct {
+	// Name 在备份中的名称，各系统共用，为空时使用 Path 的文件名
+	Name string `yaml:"name"`
+	// Path 默认路径，支持 ~ 和环境变量
+	Path string `yaml:"path"`
+	// Linux、Windows、Darwin 对应系统上的路径，为空时使用 Path
+	Linux   string `yaml:"linux"`
+	Windows string `yaml:"windows"`
+	Darwin  string `yaml:"darwin"`
+	// Excludes 排除的文件，glob 模式，匹配相对路径或文件名
+	Excludes []string `yaml:"excludes"`
+}
+
+var (
+	loadOnce sync.Once
+	loaded   *Config
+	loadErr  error
+)
+
+// Load 读取配置文件，文件不存在时返回空配置，结果会被缓存
+func Load() (*Config, error) {
+	loadOnce.Do(func() {
+		loaded, loadErr = load()
+	})
+	return loaded, loadErr
+}
+
+// Component 返回组件的配置，没有配置时返回零值。
+// 组件的其他配置（如 nvim@work）没有单独配置时使用组件本身的配置
+func (c *Config) Component(name string) ComponentConfig {
+	if cc, ok := c.Components[name]; ok {
+		return cc
+	}
+	if base, _, ok := strings.Cut(name, "@"); ok {
+		return c.Components[base]
+	}
+	return ComponentConfig{}
+}
+
+// SecretPolicy 返回组件的密钥处理策略，组件没有设置时使用全局设置
+func (c *Config) SecretPolicy(name string) string {
+	if policy := c.Component(name).Secrets; policy != "" {
+		return policy
+	}
+	return c.Secrets
+}
+
+// FilePath 返回配置文件路径
+func FilePath() (string, error) {
+	if path := os.Getenv(ConfigFileEnv); path != "" {
+		return path, nil
+	}
+
+	configHome, err := xdg.ConfigHome()
+	if err != nil {
+		return "", err
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户主目录失败: %v", err)
+	}
+	// 早期版本在所有系统上都使用 ~/.config/qs-tools，新位置没有配置文件时继续使用该位置
+	return xdg.WithLegacy(filepath.Join(configHome, "qs-tools", "config.yaml"),
+		filepath.Join(homeDir, ".config", "qs-tools", "config.yaml")), nil
+}
+
+func load() (*Config, error) {
+	cfg := &Config{}
+
+	path, err := FilePath()
+	if err != nil {
+		return cfg, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return &Config{}, fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// LocalPath 返回当前系统上的路径，展开 ~ 和环境变量
+func (p PathConfig) LocalPath() (string, error) {
+	path := p.Path
+	switch runtime.GOOS {
+	case "linux":
+		path = firstNonEmpty(p.Linux, path)
+	case "windows":
+		path = firstNonEmpty(p.Windows, path)
+	case "darwin":
+		path = firstNonEmpty(p.Darwin, path)
+	}
+	if path == "" {
+		return "", fmt.Errorf("没有配置 %s 系统上的路径", runtime.GOOS)
+	}
+	return ExpandPath(path)
+}
+
+// ArchiveName 返回在备份中的名称，各系统共用
+func (p PathConfig) ArchiveName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+
+	path := firstNonEmpty(p.Path, p.Linux, p.Windows, p.Darwin)
+	return filepath.Base(strings.ReplaceAll(path, "\\", "/"))
+}
+
+// ExpandPath 展开路径开头的 ~ 和路径中的环境变量
+func ExpandPath(path string) (string, error) {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~\\") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("获取用户主目录失败: %v", err)
+		}
+		path = filepath.Join(homeDir, path[1:])
+	}
+	return filepath.Clean(path), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
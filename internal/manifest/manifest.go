@@ -0,0 +1,249 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"qs-tools/internal/version"
+)
+
+// FileName 清单在备份归档中的文件名，总是归档的第一个条目
+const FileName = ".qs-manifest.json"
+
+// TemplateExt 模板文件的扩展名。模板在恢复时按当前主机渲染为去掉扩展名的文件，
+// 渲染结果与主机相关，备份时只备份模板，不备份渲染结果
+const TemplateExt = ".tmpl"
+
+// RemoteSuffix 恢复时按 keep-both 策略另存备份中文件所加的后缀。
+// 另存的文件只是供用户对比的副本，备份和比较时都会跳过
+const RemoteSuffix = ".qs-remote"
+
+// File 备份中的单个文件
+type File struct {
+	// Path 相对组件配置目录的 "/" 分隔路径
+	Path string `json:"path"`
+	// Size 文件大小（字节）
+	Size int64 `json:"size"`
+	// Mode 文件权限
+	Mode uint32 `json:"mode"`
+	// SHA256 文件内容的 SHA-256 哈希
+	SHA256 string `json:"sha256"`
+	// Ref 增量备份中未变化的文件不写入归档，记录内容所在的备份版本
+	Ref string `json:"ref,omitempty"`
+	// Redacted 文件中的密钥已脱敏，Size 和 SHA256 为脱敏后的内容
+	Redacted bool `json:"redacted,omitempty"`
+	// LocalSHA256 只出现在本地的同步记录中：同步后本地内容与备份不同的文件（脱敏或恢复后合并的文件）
+	// 在本地的内容的哈希，用于判断本地文件在同步后是否有修改。上传的清单中总是为空，不泄露原始内容的哈希
+	LocalSHA256 string `json:"local_sha256,omitempty"`
+	// RenderedSHA256 只出现在本地的同步记录中：模板上一次恢复时渲染结果的哈希，
+	// 用于判断渲染出的文件在同步后是否在本地被修改
+	RenderedSHA256 string `json:"rendered_sha256,omitempty"`
+}
+
+// Manifest 备份清单，描述备份的来源和内容
+type Manifest struct {
+	// Component 组件名称
+	Component string `json:"component"`
+	// SourcePath 备份时组件配置在本地的路径，有多个备份目标时以逗号分隔
+	SourcePath string `json:"source_path"`
+	// Hostname 创建备份的主机名
+	Hostname string `json:"hostname"`
+	// User 创建备份的用户
+	User string `json:"user"`
+	// OS 创建备份的操作系统
+	OS string `json:"os"`
+	// Arch 创建备份的系统架构
+	Arch string `json:"arch"`
+	// QSToolsVersion 创建备份的 qs-tools 版本
+	QSToolsVersion string `json:"qs_tools_version"`
+	// ToolVersion 组件对应工具的版本，如 fish --version 的输出
+	ToolVersion string `json:"tool_version,omitempty"`
+	// CreatedAt 备份时间
+	CreatedAt time.Time `json:"created_at"`
+	// Base 增量备份所基于的全量备份版本，全量备份为空
+	Base string `json:"base,omitempty"`
+	// Files 备份中的文件列表，按路径排序
+	Files []File `json:"files"`
+}
+
+// Build 扫描组件的备份目标并生成清单，本地不存在的目标会被跳过
+func Build(component string, targets Targets, toolVersion string) (*Manifest, error) {
+	m := &Manifest{
+		Component:      component,
+		SourcePath:     strings.Join(targets.Paths(), ", "),
+		OS:             runtime.GOOS,
+		Arch:           runtime.GOARCH,
+		QSToolsVersion: version.Version,
+		ToolVersion:    toolVersion,
+		CreatedAt:      time.Now(),
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		m.Hostname = hostname
+	}
+	if u, err := user.Current(); err == nil {
+		m.User = u.Username
+	}
+
+	for _, t := range targets {
+		if !t.exists() {
+			continue
+		}
+		if err := m.addTarget(t); err != nil {
+			return nil, fmt.Errorf("生成备份清单失败: %v", err)
+		}
+	}
+
+	sort.Slice(m.Files, func(i, j int) bool {
+		return m.Files[i].Path < m.Files[j].Path
+	})
+	return m, nil
+}
+
+// addTarget 扫描单个备份目标，将其中的普通文件加入清单，由模板渲染生成的文件和另存的备份副本除外
+func (m *Manifest) addTarget(t Target) error {
+	return filepath.Walk(t.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(t.Path, path)
+		if err != nil {
+			return err
+		}
+		if rel != "." && t.excluded(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if _, err := os.Stat(path + TemplateExt); err == nil {
+			return nil
+		}
+		if strings.HasSuffix(path, RemoteSuffix) {
+			return nil
+		}
+
+		sum, err := HashFile(path)
+		if err != nil {
+			return err
+		}
+
+		m.Files = append(m.Files, File{
+			Path:   t.archiveName(rel),
+			Size:   info.Size(),
+			Mode:   uint32(info.Mode().Perm()),
+			SHA256: sum,
+		})
+		return nil
+	})
+}
+
+// Read 从 JSON 数据中读取清单
+func Read(r io.Reader) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("解析备份清单失败: %v", err)
+	}
+	return &m, nil
+}
+
+// Marshal 将清单序列化为 JSON
+func (m *Manifest) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("生成备份清单失败: %v", err)
+	}
+	return data, nil
+}
+
+// TreeHash 计算整个配置目录的哈希，只与文件路径、权限和内容有关，
+// 用于判断两次备份之间配置是否有变化
+func (m *Manifest) TreeHash() string {
+	h := sha256.New()
+	for _, f := range m.Files {
+		fmt.Fprintf(h, "%s\x00%o\x00%s\n", f.Path, f.Mode, f.SHA256)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// MarkUnchanged 将与上一次备份相比内容和权限都没有变化的文件标记为引用已有的备份版本，
+// 这些文件不会再写入归档。prevID 为上一次备份的版本号，返回需要写入归档的文件数
+func (m *Manifest) MarkUnchanged(prev *Manifest, prevID string) int {
+	prevFiles := make(map[string]File, len(prev.Files))
+	for _, f := range prev.Files {
+		prevFiles[f.Path] = f
+	}
+
+	stored := 0
+	for i := range m.Files {
+		f := &m.Files[i]
+		old, ok := prevFiles[f.Path]
+		if !ok || old.SHA256 != f.SHA256 || old.Mode != f.Mode {
+			stored++
+			continue
+		}
+
+		f.Ref = old.Ref
+		if f.Ref == "" {
+			f.Ref = prevID
+		}
+	}
+	return stored
+}
+
+// Refs 按内容所在的备份版本对文件分组，id 为当前备份的版本号，
+// 返回版本号到该版本中需要读取的文件路径集合
+func (m *Manifest) Refs(id string) map[string]map[string]bool {
+	refs := make(map[string]map[string]bool)
+	for _, f := range m.Files {
+		ref := f.Ref
+		if ref == "" {
+			ref = id
+		}
+		if refs[ref] == nil {
+			refs[ref] = make(map[string]bool)
+		}
+		refs[ref][f.Path] = true
+	}
+	return refs
+}
+
+// Filter 只保留路径匹配任一 glob 模式的文件，模式的含义见 Match
+func (m *Manifest) Filter(patterns []string) {
+	files := m.Files[:0]
+	for _, f := range m.Files {
+		if Match(patterns, f.Path) {
+			files = append(files, f)
+		}
+	}
+	m.Files = files
+}
+
+// HashFile 计算文件内容的 SHA-256 哈希
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
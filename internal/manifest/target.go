@@ -0,0 +1,120 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Target 备份目标，把本地的文件或目录映射到归档中与操作系统无关的路径，
+// 恢复时再按当前系统的目标路径还原
+type Target struct {
+	// Name 归档中的路径，为空表示归档根目录（只能用于目录）
+	Name string
+	// Path 当前系统上的文件或目录路径
+	Path string
+	// Excludes 排除的文件和目录，glob 模式，匹配相对目标的路径或文件名
+	Excludes []string
+}
+
+// Targets 组件的全部备份目标
+type Targets []Target
+
+// Paths 返回所有目标在当前系统上的路径
+func (ts Targets) Paths() []string {
+	paths := make([]string, 0, len(ts))
+	for _, t := range ts {
+		paths = append(paths, t.Path)
+	}
+	return paths
+}
+
+// Relocate 返回把全部目标移到 dir 下的备份目标，用于恢复到其他目录：
+// 归档根目录对应 dir，其他目标对应 dir 下与归档中同名的路径
+func (ts Targets) Relocate(dir string) Targets {
+	relocated := make(Targets, 0, len(ts))
+	for _, t := range ts {
+		t.Path = filepath.Join(dir, filepath.FromSlash(t.Name))
+		relocated = append(relocated, t)
+	}
+	return relocated
+}
+
+// Resolve 将归档中的路径映射为当前系统上的路径，拒绝跳出目标目录的路径
+func (ts Targets) Resolve(name string) (string, error) {
+	var best *Target
+	for i := range ts {
+		t := &ts[i]
+		if t.Name != "" && name != t.Name && !strings.HasPrefix(name, t.Name+"/") {
+			continue
+		}
+		if best == nil || len(t.Name) > len(best.Name) {
+			best = t
+		}
+	}
+	if best == nil {
+		return "", fmt.Errorf("归档中的路径不属于任何备份目标: %s", name)
+	}
+
+	rel := strings.TrimPrefix(strings.TrimPrefix(name, best.Name), "/")
+	if rel == "" {
+		return best.Path, nil
+	}
+
+	rel = path.Clean(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") || path.IsAbs(rel) {
+		return "", fmt.Errorf("归档中包含非法路径: %s", name)
+	}
+	return filepath.Join(best.Path, filepath.FromSlash(rel)), nil
+}
+
+// archiveName 返回目标中相对路径为 rel 的文件在归档中的路径
+func (t Target) archiveName(rel string) string {
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		return t.Name
+	}
+	if t.Name == "" {
+		return rel
+	}
+	return t.Name + "/" + rel
+}
+
+// excluded 判断目标中相对路径为 rel 的文件或目录是否被排除
+func (t Target) excluded(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range t.Excludes {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// exists 判断目标在当前系统上是否存在
+func (t Target) exists() bool {
+	_, err := os.Stat(t.Path)
+	return err == nil
+}
+
+// Match 判断归档中的路径 name 是否匹配任一 glob 模式。
+// 模式可以匹配路径本身、路径所在的任一上级目录（即整个子目录）或文件名，末尾的 "/" 会被忽略
+func Match(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(pattern, "/")
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			return true
+		}
+		for p := name; p != "." && p != "/"; p = path.Dir(p) {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,283 @@
+package utils
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// BundleExt 离线包的扩展名
+const BundleExt = ".qsb"
+
+// bundleInfoName 离线包中描述包内容的文件，位于离线包的开头
+const bundleInfoName = "qs-bundle.json"
+
+// bundleFormat 离线包格式的版本
+const bundleFormat = 1
+
+// BundleInfo 离线包的说明，记录导出的主机和包中每个组件的备份版本
+type BundleInfo struct {
+	// Format 离线包格式的版本
+	Format int `json:"format"`
+	// Hostname 导出离线包的主机名
+	Hostname string `json:"hostname"`
+	// CreatedAt 导出时间
+	CreatedAt time.Time `json:"created_at"`
+	// Versions 包中的备份版本，与远程服务器索引中的记录相同
+	Versions []BackupMeta `json:"versions"`
+}
+
+// Bundle 离线包，用于在无法连接远程服务器的主机之间传递备份。
+// 包是一个 tar 文件，开头为 qs-bundle.json，之后按远程服务器上的目录结构保存各组件的备份，
+// 备份本身与上传到远程服务器的归档相同。Bundle 实现了 Store，导出和导入时代替远程服务器
+type Bundle struct {
+	path     string
+	info     BundleInfo
+	archives map[string][]byte
+}
+
+// NewBundle 创建一个空的离线包，调用 Save 后写入 path
+func NewBundle(path string) *Bundle {
+	hostname, _ := os.Hostname()
+	return &Bundle{
+		path: path,
+		info: BundleInfo{
+			Format:    bundleFormat,
+			Hostname:  hostname,
+			CreatedAt: time.Now(),
+		},
+		archives: make(map[string][]byte),
+	}
+}
+
+// OpenBundle 读取离线包
+func OpenBundle(path string) (*Bundle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开离线包失败: %v", err)
+	}
+	defer file.Close()
+
+	b := &Bundle{path: path, archives: make(map[string][]byte)}
+	tr := tar.NewReader(file)
+	header, err := tr.Next()
+	if err != nil || header.Name != bundleInfoName {
+		return nil, fmt.Errorf("%s 不是有效的离线包", path)
+	}
+	if err := json.NewDecoder(tr).Decode(&b.info); err != nil {
+		return nil, fmt.Errorf("解析离线包说明失败: %v", err)
+	}
+	if b.info.Format > bundleFormat {
+		return nil, fmt.Errorf("离线包格式版本 %d 过新，请升级 qs-tools", b.info.Format)
+	}
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取离线包失败: %v", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("读取离线包失败: %v", err)
+		}
+		b.archives[header.Name] = data
+	}
+
+	for _, v := range b.info.Versions {
+		if _, ok := b.archives[bundleArchiveName(v.Component, v.ID)]; !ok {
+			return nil, fmt.Errorf("离线包不完整: 缺少 %s 的备份 %s", v.Component, v.ID)
+		}
+	}
+	return b, nil
+}
+
+// Info 返回离线包的说明
+func (b *Bundle) Info() *BundleInfo {
+	return &b.info
+}
+
+// Components 返回离线包中的组件名称，按名称排序
+func (b *Bundle) Components() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, v := range b.info.Versions {
+		if !seen[v.Component] {
+			seen[v.Component] = true
+			names = append(names, v.Component)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Save 将离线包写入文件，先写入临时文件，完成后再替换
+func (b *Bundle) Save() error {
+	if dir := filepath.Dir(b.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建目录失败: %v", err)
+		}
+	}
+
+	tmp := b.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("创建离线包失败: %v", err)
+	}
+
+	if err := b.write(file); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("写入离线包失败: %v", err)
+	}
+	if err := os.Rename(tmp, b.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("保存离线包失败: %v", err)
+	}
+	return nil
+}
+
+// write 按 qs-bundle.json、各组件备份的顺序写出离线包
+func (b *Bundle) write(w io.Writer) error {
+	info, err := json.MarshalIndent(b.info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("生成离线包说明失败: %v", err)
+	}
+
+	tw := tar.NewWriter(w)
+	if err := b.writeEntry(tw, bundleInfoName, info); err != nil {
+		return err
+	}
+	for _, v := range b.info.Versions {
+		name := bundleArchiveName(v.Component, v.ID)
+		if err := b.writeEntry(tw, name, b.archives[name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("写入离线包失败: %v", err)
+	}
+	return nil
+}
+
+// writeEntry 向离线包写入一个文件
+func (b *Bundle) writeEntry(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: b.info.CreatedAt,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("写入离线包失败: %v", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("写入离线包失败: %v", err)
+	}
+	return nil
+}
+
+// bundleArchiveName 返回备份在离线包中的路径，与远程服务器上的目录结构相同
+func bundleArchiveName(component, id string) string {
+	return path.Join(component, id)
+}
+
+// index 返回离线包中组件的备份索引
+func (b *Bundle) index(component string) *backupIndex {
+	index := &backupIndex{}
+	for _, v := range b.info.Versions {
+		if v.Component == component {
+			index.Versions = append(index.Versions, v)
+		}
+	}
+	return index
+}
+
+// setIndex 用组件的备份索引替换离线包中该组件的记录
+func (b *Bundle) setIndex(component string, index *backupIndex) {
+	versions := b.info.Versions[:0]
+	for _, v := range b.info.Versions {
+		if v.Component != component {
+			versions = append(versions, v)
+		}
+	}
+	b.info.Versions = append(versions, index.Versions...)
+}
+
+// List 列出离线包中组件的所有备份版本
+func (b *Bundle) List(component string) ([]BackupMeta, error) {
+	return b.index(component).Versions, nil
+}
+
+// ListComponents 列出离线包中的组件名称，按名称排序
+func (b *Bundle) ListComponents() ([]string, error) {
+	return b.Components(), nil
+}
+
+// RecordsSync 离线包不是与远程服务器的同步，导出和导入都不更新同步记录
+func (b *Bundle) RecordsSync() bool {
+	return false
+}
+
+// Download 从离线包中读取组件的备份，version 为空时读取最新版本
+func (b *Bundle) Download(component, version string, read func(r io.Reader, meta *BackupMeta) error) (*BackupMeta, error) {
+	index := b.index(component)
+	if len(index.Versions) == 0 {
+		return nil, fmt.Errorf("离线包中没有 %s 的备份", component)
+	}
+	meta, err := index.find(component, version)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("正在从离线包读取 %s 的备份 %s...\n", component, meta.ID)
+	data := b.archives[bundleArchiveName(component, meta.ID)]
+	if err := read(bytes.NewReader(data), meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// Tag 为离线包中组件的备份版本添加标签
+func (b *Bundle) Tag(component, version, tag string) (*BackupMeta, error) {
+	index := b.index(component)
+	meta, err := index.find(component, version)
+	if err != nil {
+		return nil, err
+	}
+	id := meta.ID
+
+	index.tag(id, tag)
+	b.setIndex(component, index)
+	return index.find(component, id)
+}
+
+// Upload 将 write 写出的备份加入离线包
+func (b *Bundle) Upload(meta *BackupMeta, write func(w io.Writer) error) error {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return err
+	}
+
+	index := b.index(meta.Component)
+	meta.ID = index.uniqueID(meta.ID, nil)
+	index.add(meta)
+	b.setIndex(meta.Component, index)
+
+	b.archives[bundleArchiveName(meta.Component, meta.ID)] = buf.Bytes()
+	fmt.Printf("已加入离线包: %s\n", bundleArchiveName(meta.Component, meta.ID))
+	return nil
+}
@@ -1,64 +1,239 @@
 package utils
 
 import (
+	"archive/tar"
+	"compress/gzip"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"runtime"
+	"strings"
+	"time"
+
+	"qs-tools/internal/manifest"
 )
 
-// CompressDir 压缩目录
-func CompressDir(sourceDir, targetFile string) error {
-	fmt.Println("正在压缩文件...")
-
-	if runtime.GOOS == "windows" {
-		// Windows 使用 PowerShell 压缩
-		cmd := exec.Command("powershell", "-Command",
-			fmt.Sprintf("Compress-Archive -Path '%s\\*' -DestinationPath '%s' -Force",
-				sourceDir, targetFile))
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("压缩文件失败: %v", err)
+// FormatTarGz 备份归档格式，所有系统统一使用 tar.gz，
+// 归档内路径均为相对组件配置目录的 "/" 分隔路径，与操作系统无关
+const FormatTarGz = "tar.gz"
+
+// WriteArchive 按清单将备份目标打包压缩后写入 w，清单作为归档的第一个条目写入，
+// 归档内只保存与操作系统无关的路径。redacted 中的文件写入其中脱敏后的内容
+func WriteArchive(w io.Writer, targets manifest.Targets, m *manifest.Manifest, redacted map[string][]byte) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	// 写入清单
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    manifest.FileName,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: m.CreatedAt,
+	}); err != nil {
+		return fmt.Errorf("压缩文件失败: %v", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("压缩文件失败: %v", err)
+	}
+
+	// 写入清单中的文件，增量备份中引用其他版本的文件不写入
+	for _, f := range m.Files {
+		if f.Ref != "" {
+			continue
 		}
-	} else {
-		// Linux 使用 tar
-		cmd := exec.Command("tar", "czf", targetFile,
-			"-C", filepath.Dir(sourceDir), filepath.Base(sourceDir))
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
+		if content, ok := redacted[f.Path]; ok {
+			if err := addContent(tw, f, content, m.CreatedAt); err != nil {
+				return fmt.Errorf("压缩文件失败: %v", err)
+			}
+			continue
+		}
+		if err := addFile(tw, targets, f); err != nil {
 			return fmt.Errorf("压缩文件失败: %v", err)
 		}
 	}
 
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("压缩文件失败: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("压缩文件失败: %v", err)
+	}
 	return nil
 }
 
-// ExtractFile 解压文件
-func ExtractFile(sourceFile, targetDir string) error {
-	fmt.Println("正在解压文件...")
-
-	if runtime.GOOS == "windows" {
-		// Windows 使用 PowerShell 解压
-		cmd := exec.Command("powershell", "-Command",
-			fmt.Sprintf("Expand-Archive -Path '%s' -DestinationPath '%s' -Force",
-				sourceFile, targetDir))
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("解压文件失败: %v", err)
+// addFile 将清单中的文件写入归档
+func addFile(tw *tar.Writer, targets manifest.Targets, f manifest.File) error {
+	path, err := targets.Resolve(f.Path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = f.Path
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err = io.CopyN(tw, file, header.Size)
+	return err
+}
+
+// ResolveFunc 决定归档中的文件写入的路径，target 为按备份目标映射的路径，
+// 返回空字符串表示跳过该文件
+type ResolveFunc func(name, target string, header *tar.Header) (string, error)
+
+// addContent 将清单中的文件以指定内容写入归档
+func addContent(tw *tar.Writer, f manifest.File, content []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    f.Path,
+		Mode:    int64(f.Mode),
+		Size:    int64(len(content)),
+		ModTime: modTime,
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(content)
+	return err
+}
+
+// ExtractArchive 从 r 中读取归档并按备份目标解压到当前系统的路径，
+// include 不为 nil 时只解压其中的文件，resolve 不为 nil 时由其决定文件的写入路径
+func ExtractArchive(r io.Reader, targets manifest.Targets, include map[string]bool, resolve ResolveFunc) error {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("解压文件失败: %v", err)
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
 		}
-	} else {
-		// Linux 使用 tar
-		cmd := exec.Command("tar", "xzf", sourceFile, "-C", targetDir)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		if err := cmd.Run(); err != nil {
+		if err != nil {
 			return fmt.Errorf("解压文件失败: %v", err)
 		}
+
+		// 清单只用于描述备份，不解压到配置目录
+		if header.Name == manifest.FileName {
+			continue
+		}
+		if include != nil && !include[header.Name] {
+			continue
+		}
+
+		target, err := targets.Resolve(strings.TrimSuffix(header.Name, "/"))
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("创建目录失败: %v", err)
+			}
+		case tar.TypeReg:
+			if resolve != nil {
+				if target, err = resolve(header.Name, target, header); err != nil {
+					return err
+				}
+				if target == "" {
+					continue
+				}
+			}
+			if err := writeFile(target, tr, os.FileMode(header.Mode).Perm()); err != nil {
+				return fmt.Errorf("写入文件失败: %v", err)
+			}
+		}
 	}
 
 	return nil
-} 
+}
+
+// ReadArchiveFiles 从 r 中读取归档，返回 include 中各文件的内容，键为归档中的路径
+func ReadArchiveFiles(r io.Reader, include map[string]bool) (map[string][]byte, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("解压文件失败: %v", err)
+	}
+	defer gzr.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解压文件失败: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg || !include[header.Name] {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("解压文件失败: %v", err)
+		}
+		files[header.Name] = data
+	}
+	return files, nil
+}
+
+// ReadManifest 从 r 中读取归档的备份清单，只读取归档开头的清单条目
+func ReadManifest(r io.Reader) (*manifest.Manifest, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("读取备份清单失败: %v", err)
+	}
+	defer gzr.Close()
+
+	// 清单总是归档的第一个条目
+	tr := tar.NewReader(gzr)
+	header, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("读取备份清单失败: %v", err)
+	}
+	if header.Name != manifest.FileName {
+		return nil, fmt.Errorf("备份中没有清单")
+	}
+	return manifest.Read(tr)
+}
+
+// writeFile 将内容写入文件，必要时创建父目录
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"archive/tar"
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"qs-tools/internal/manifest"
+)
+
+// ConflictPolicy 恢复时本地有修改的文件的处理策略
+type ConflictPolicy string
+
+const (
+	// ConflictOverwrite 用备份覆盖本地文件
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictKeepLocal 保留本地文件，不恢复备份中的文件
+	ConflictKeepLocal ConflictPolicy = "keep-local"
+	// ConflictKeepBoth 保留本地文件，备份中的文件加上 RemoteSuffix 后缀另存
+	ConflictKeepBoth ConflictPolicy = "keep-both"
+	// ConflictNewerWins 保留修改时间较新的文件
+	ConflictNewerWins ConflictPolicy = "newer-wins"
+	// ConflictPrompt 逐个文件询问
+	ConflictPrompt ConflictPolicy = "prompt"
+)
+
+// RemoteSuffix keep-both 策略下备份中的文件另存时添加的后缀
+const RemoteSuffix = manifest.RemoteSuffix
+
+// ConflictPolicies 全部冲突处理策略，用于帮助信息和参数检查
+var ConflictPolicies = []ConflictPolicy{
+	ConflictOverwrite, ConflictKeepLocal, ConflictKeepBoth, ConflictNewerWins, ConflictPrompt,
+}
+
+// ParseConflictPolicy 解析冲突处理策略，为空时使用 ConflictOverwrite
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	if s == "" {
+		return ConflictOverwrite, nil
+	}
+	for _, p := range ConflictPolicies {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("不支持的冲突处理策略: %s（可选 %s）", s, strings.Join(ConflictPolicyNames(), "、"))
+}
+
+// ConflictPolicyNames 返回全部冲突处理策略的名称
+func ConflictPolicyNames() []string {
+	names := make([]string, 0, len(ConflictPolicies))
+	for _, p := range ConflictPolicies {
+		names = append(names, string(p))
+	}
+	return names
+}
+
+// findConflicts 找出本地有修改且与备份不同的文件。
+// 本地文件与上一次同步（恢复或备份）的清单不同即视为本地有修改，没有同步记录时与备份不同的本地文件都视为冲突。
+// 脱敏的文件不参与比较
+func findConflicts(m *manifest.Manifest, targets manifest.Targets) (map[string]bool, error) {
+	local, err := manifest.Build(m.Component, targets, "")
+	if err != nil {
+		return nil, err
+	}
+	synced, err := LoadSynced(m.Component)
+	if err != nil {
+		fmt.Printf("⚠️ %v，与备份不同的本地文件都将视为冲突\n", err)
+	}
+
+	localFiles := make(map[string]manifest.File, len(local.Files))
+	for _, f := range local.Files {
+		localFiles[f.Path] = f
+	}
+	syncedFiles := make(map[string]manifest.File)
+	if synced != nil {
+		for _, f := range synced.Files {
+			syncedFiles[f.Path] = f
+		}
+	}
+
+	conflicts := make(map[string]bool)
+	for _, f := range m.Files {
+		// 脱敏的文件在本地已有时总是保留本地文件（见 keepRedacted），不会产生冲突
+		if f.Redacted {
+			continue
+		}
+		l, ok := localFiles[f.Path]
+		if !ok || l.SHA256 == f.SHA256 {
+			continue
+		}
+		// 同步后本地内容与备份不同的文件（见 RecordLocalChanges）按其在本地的哈希判断
+		if a, ok := syncedFiles[f.Path]; ok && (a.SHA256 == l.SHA256 || a.LocalSHA256 == l.SHA256) {
+			continue
+		}
+		conflicts[f.Path] = true
+	}
+	return conflicts, nil
+}
+
+// conflictResolver 按策略决定冲突文件的写入位置
+type conflictResolver struct {
+	policy    ConflictPolicy
+	conflicts map[string]bool
+	// all 交互模式下选择了对剩余文件使用的策略
+	all   ConflictPolicy
+	stdin *bufio.Reader
+}
+
+// resolve 返回备份中的文件应写入的路径，返回空字符串表示不写入
+func (cr *conflictResolver) resolve(name, target string, header *tar.Header) (string, error) {
+	if !cr.conflicts[name] {
+		return target, nil
+	}
+
+	policy := cr.policy
+	if policy == ConflictPrompt {
+		p, err := cr.ask(name, target, header)
+		if err != nil {
+			return "", err
+		}
+		policy = p
+	}
+
+	switch policy {
+	case ConflictKeepLocal:
+		fmt.Printf("保留本地文件: %s\n", name)
+		return "", nil
+	case ConflictKeepBoth:
+		fmt.Printf("保留本地文件，备份另存为: %s%s\n", name, RemoteSuffix)
+		return target + RemoteSuffix, nil
+	case ConflictNewerWins:
+		info, err := os.Stat(target)
+		if err == nil && info.ModTime().After(header.ModTime) {
+			fmt.Printf("本地文件较新，保留: %s\n", name)
+			return "", nil
+		}
+		fmt.Printf("备份中的文件较新，覆盖: %s\n", name)
+		return target, nil
+	default:
+		fmt.Printf("覆盖本地修改: %s\n", name)
+		return target, nil
+	}
+}
+
+// ask 询问冲突文件的处理方式，选择大写字母时对剩余的冲突文件使用相同的策略
+func (cr *conflictResolver) ask(name, target string, header *tar.Header) (ConflictPolicy, error) {
+	if cr.all != "" {
+		return cr.all, nil
+	}
+	if cr.stdin == nil {
+		cr.stdin = bufio.NewReader(os.Stdin)
+	}
+
+	fmt.Printf("\n文件 %s 在本地有修改，与备份不同\n", name)
+	if info, err := os.Stat(target); err == nil {
+		fmt.Printf("  本地: %d 字节，修改于 %s\n", info.Size(), info.ModTime().Format("2006-01-02 15:04:05"))
+	}
+	fmt.Printf("  备份: %d 字节，修改于 %s\n", header.Size, header.ModTime.Local().Format("2006-01-02 15:04:05"))
+
+	for {
+		fmt.Print("[o] 覆盖 [k] 保留本地 [b] 都保留（大写表示对剩余文件都如此）: ")
+		line, err := cr.stdin.ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("读取输入失败: %v", err)
+		}
+
+		answer := strings.TrimSpace(line)
+		var policy ConflictPolicy
+		switch strings.ToLower(answer) {
+		case "o":
+			policy = ConflictOverwrite
+		case "k":
+			policy = ConflictKeepLocal
+		case "b":
+			policy = ConflictKeepBoth
+		default:
+			continue
+		}
+		if answer != strings.ToLower(answer) {
+			cr.all = policy
+		}
+		return policy, nil
+	}
+}
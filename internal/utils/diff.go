@@ -0,0 +1,217 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ChangeKind 恢复时文件的变化类型
+type ChangeKind string
+
+const (
+	// ChangeAdded 备份中有、本地没有的文件
+	ChangeAdded ChangeKind = "added"
+	// ChangeModified 备份与本地内容或权限不同的文件
+	ChangeModified ChangeKind = "modified"
+	// ChangeDeleted 本地有、备份中没有的文件
+	ChangeDeleted ChangeKind = "deleted"
+)
+
+// FileChange 恢复时一个文件的变化
+type FileChange struct {
+	// Path 归档中的路径
+	Path string
+	Kind ChangeKind
+	// LocalPath 当前系统上的路径
+	LocalPath string
+	// Local 本地文件内容，新增的文件为空
+	Local []byte
+	// Remote 备份中的文件内容，删除的文件为空
+	Remote []byte
+	// LocalMode、RemoteMode 本地和备份中的文件权限
+	LocalMode  os.FileMode
+	RemoteMode os.FileMode
+}
+
+// maxDiffLines 超过该行数的文件不计算差异，避免占用过多内存
+const maxDiffLines = 5000
+
+// diffContext 差异中每处修改前后显示的上下文行数
+const diffContext = 3
+
+// PrintChanges 打印恢复会带来的文件变化和文本文件的差异
+func PrintChanges(changes []FileChange) {
+	if len(changes) == 0 {
+		fmt.Println("本地配置与备份相同，没有需要恢复的变化")
+		return
+	}
+
+	var added, modified, deleted int
+	fmt.Println("\n文件变化：")
+	for _, c := range changes {
+		switch c.Kind {
+		case ChangeAdded:
+			added++
+			fmt.Printf("  + %s\n", c.Path)
+		case ChangeModified:
+			modified++
+			fmt.Printf("  ~ %s\n", c.Path)
+		case ChangeDeleted:
+			deleted++
+			fmt.Printf("  - %s（备份中没有该文件，恢复时不会删除）\n", c.Path)
+		}
+	}
+	fmt.Printf("共 %d 个新增，%d 个修改，%d 个删除\n", added, modified, deleted)
+
+	for _, c := range changes {
+		if c.Kind == ChangeDeleted {
+			continue
+		}
+		fmt.Println()
+		if c.Kind == ChangeModified && c.LocalMode != c.RemoteMode {
+			fmt.Printf("权限变化 %s: %v -> %v\n", c.Path, c.LocalMode, c.RemoteMode)
+			if bytes.Equal(c.Local, c.Remote) {
+				continue
+			}
+		}
+		fromName := "a/" + c.Path
+		if c.Kind == ChangeAdded {
+			fromName = "/dev/null"
+		}
+		fmt.Print(UnifiedDiff(fromName, "b/"+c.Path, c.Local, c.Remote))
+	}
+}
+
+// UnifiedDiff 生成统一格式的文本差异，二进制文件或过大的文件只给出说明
+func UnifiedDiff(fromName, toName string, from, to []byte) string {
+	if isBinary(from) || isBinary(to) {
+		return fmt.Sprintf("二进制文件 %s 和 %s 不同\n", fromName, toName)
+	}
+
+	a, b := splitLines(from), splitLines(to)
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		return fmt.Sprintf("文件 %s 过大，不显示差异\n", toName)
+	}
+
+	ops := diffLines(a, b)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	for _, h := range hunks(ops) {
+		out.WriteString(h)
+	}
+	return out.String()
+}
+
+// diffOp 差异中的一行，kind 为 ' '、'-' 或 '+'
+type diffOp struct {
+	kind byte
+	line string
+	// ai、bi 该行在原文件和新文件中的行号（从 0 开始）
+	ai, bi int
+}
+
+// diffLines 基于最长公共子序列计算逐行差异
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], i, j})
+			i++
+			j++
+		case j < m && (i == n || lcs[i][j+1] > lcs[i+1][j]):
+			ops = append(ops, diffOp{'+', b[j], i, j})
+			j++
+		default:
+			ops = append(ops, diffOp{'-', a[i], i, j})
+			i++
+		}
+	}
+	return ops
+}
+
+// hunks 将逐行差异按修改位置分组，每组保留前后若干行上下文
+func hunks(ops []diffOp) []string {
+	var result []string
+	for start := 0; start < len(ops); {
+		// 找到下一处修改
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// 向后合并间隔不超过两倍上下文的修改
+		end := start
+		for k := start; k < len(ops); k++ {
+			if ops[k].kind != ' ' {
+				end = k
+			} else if k-end > 2*diffContext {
+				break
+			}
+		}
+
+		from := max(start-diffContext, 0)
+		to := min(end+diffContext+1, len(ops))
+
+		var b strings.Builder
+		aStart, bStart := ops[from].ai, ops[from].bi
+		var aCount, bCount int
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				aCount++
+			}
+			if op.kind != '-' {
+				bCount++
+			}
+			b.WriteByte(op.kind)
+			b.WriteString(op.line)
+			b.WriteByte('\n')
+		}
+		result = append(result, fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))+b.String())
+		start = to
+	}
+	return result
+}
+
+// hunkRange 返回统一格式差异中的行号范围
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data, 0) >= 0
+}
@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// 加密流格式：
+//
+//	magic(6) | salt(16) | chunk | chunk | ... | 最后一个 chunk
+//
+// 数据按 64KB 分块使用 AES-256-GCM 加密，每块的 nonce 由块序号和
+// 结束标记组成，可以在边读边写的同时发现截断、重排和篡改。
+const (
+	encryptMagic     = "QSENC1"
+	encryptSaltSize  = 16
+	encryptChunkSize = 64 * 1024
+)
+
+// deriveKey 由口令和盐派生 AES-256 密钥
+func deriveKey(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("派生密钥失败: %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce 生成第 counter 块的 nonce，最后一块带有结束标记
+func chunkNonce(counter uint64, last bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], counter)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// encryptWriter 分块加密写入器
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+}
+
+// EncryptWriter 返回一个加密写入器，写入的数据加密后写入 w，
+// 必须调用 Close 写出最后一块，Close 不会关闭 w
+func EncryptWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	salt := make([]byte, encryptSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("生成随机盐失败: %v", err)
+	}
+	aead, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write([]byte(encryptMagic)); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(salt); err != nil {
+		return nil, err
+	}
+
+	return &encryptWriter{
+		w:    w,
+		aead: aead,
+		buf:  make([]byte, 0, encryptChunkSize),
+	}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) > 0 {
+		// 缓冲区已满且还有后续数据，说明当前块不是最后一块
+		if len(e.buf) == encryptChunkSize {
+			if err := e.flush(false); err != nil {
+				return n, err
+			}
+		}
+
+		m := copy(e.buf[len(e.buf):encryptChunkSize], p)
+		e.buf = e.buf[:len(e.buf)+m]
+		p = p[m:]
+		n += m
+	}
+	return n, nil
+}
+
+// Close 写出最后一块
+func (e *encryptWriter) Close() error {
+	return e.flush(true)
+}
+
+func (e *encryptWriter) flush(last bool) error {
+	sealed := e.aead.Seal(nil, chunkNonce(e.counter, last), e.buf, nil)
+	if _, err := e.w.Write(sealed); err != nil {
+		return err
+	}
+	e.counter++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// decryptReader 分块解密读取器
+type decryptReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	chunk   []byte
+	plain   []byte
+	counter uint64
+	done    bool
+}
+
+// DecryptReader 返回一个解密读取器，从 r 中读取 EncryptWriter 生成的数据并解密
+func DecryptReader(r io.Reader, passphrase string) (io.Reader, error) {
+	header := make([]byte, len(encryptMagic)+encryptSaltSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("读取加密头失败: %v", err)
+	}
+	if string(header[:len(encryptMagic)]) != encryptMagic {
+		return nil, errors.New("不是有效的加密备份")
+	}
+
+	aead, err := deriveKey(passphrase, header[len(encryptMagic):])
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptReader{
+		r:     bufio.NewReaderSize(r, encryptChunkSize),
+		aead:  aead,
+		chunk: make([]byte, encryptChunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// next 读取并解密下一块
+func (d *decryptReader) next() error {
+	n, err := io.ReadFull(d.r, d.chunk)
+	last := false
+	switch err {
+	case nil:
+		// 整块读满时，只有后面没有数据才是最后一块
+		if _, err := d.r.Peek(1); err == io.EOF {
+			last = true
+		}
+	case io.ErrUnexpectedEOF:
+		last = true
+	case io.EOF:
+		return errors.New("加密数据被截断")
+	default:
+		return err
+	}
+
+	plain, err := d.aead.Open(d.chunk[:0], chunkNonce(d.counter, last), d.chunk[:n], nil)
+	if err != nil {
+		return errors.New("解密失败，口令错误或数据已损坏")
+	}
+
+	d.counter++
+	d.plain = plain
+	d.done = last
+	return nil
+}
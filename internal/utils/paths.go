@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"qs-tools/internal/xdg"
+)
+
+// ComponentConfigDir 返回组件在当前系统上的配置目录，与工具本身查找配置的方式一致。
+// 备份归档中只保存相对该目录的路径，恢复时再映射到当前系统的目录：
+//   - nvim: $XDG_CONFIG_HOME/nvim，未设置时 Windows 为 %LOCALAPPDATA%\nvim，其他系统为 ~/.config/nvim
+//   - yazi: $YAZI_CONFIG_HOME，未设置时 Windows 为 %APPDATA%\yazi\config，其他系统为 $XDG_CONFIG_HOME/yazi
+//   - 其他组件: $XDG_CONFIG_HOME/<component>，未设置时为 ~/.config/<component>
+func ComponentConfigDir(component string) (string, error) {
+	switch component {
+	case "nvim":
+		if runtime.GOOS == "windows" && xdg.Env("XDG_CONFIG_HOME") == "" {
+			localAppData, err := xdg.LocalAppData()
+			if err != nil {
+				return "", err
+			}
+			return filepath.Join(localAppData, "nvim"), nil
+		}
+	case "yazi":
+		if dir := xdg.Env("YAZI_CONFIG_HOME"); dir != "" {
+			return dir, nil
+		}
+		if runtime.GOOS == "windows" {
+			appData, err := xdg.AppData()
+			if err != nil {
+				return "", err
+			}
+			return filepath.Join(appData, "yazi", "config"), nil
+		}
+	}
+
+	configHome, err := xdg.ConfigHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configHome, component), nil
+}
+
+// StateDir 返回 qs-tools 在本地保存状态数据的目录 $XDG_STATE_HOME/qs-tools，
+// 默认为 ~/.local/state/qs-tools（Windows 为 %LOCALAPPDATA%\qs-tools）。
+// 早期版本在所有系统上都使用 ~/.local/state/qs-tools，新位置不存在时继续使用该目录
+func StateDir() (string, error) {
+	stateHome, err := xdg.StateHome()
+	if err != nil {
+		return "", err
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户主目录失败: %v", err)
+	}
+	return xdg.WithLegacy(filepath.Join(stateHome, "qs-tools"), filepath.Join(homeDir, ".local", "state", "qs-tools")), nil
+}
@@ -0,0 +1,551 @@
+package utils
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"qs-tools/internal/config"
+	"qs-tools/internal/manifest"
+)
+
+// BackupOptions 备份选项
+type BackupOptions struct {
+	// Full 强制进行全量备份
+	Full bool
+	// Force 配置没有变化时也上传新的备份
+	Force bool
+	// Tag 为备份添加的标签，配置没有变化时添加到最新的备份上
+	Tag string
+	// Secrets 发现疑似密钥时的处理策略，为空时取消备份
+	Secrets SecretPolicy
+	// Store 备份的存储位置，为空时上传到远程服务器
+	Store Store
+	// Virtual 备份目标是组件临时导出的目录（如 scoop export 的结果），不是本地的配置文件，
+	// 不更新同步记录
+	Virtual bool
+}
+
+// BackupResult 备份结果
+type BackupResult struct {
+	// Meta 本次上传的备份版本，跳过上传时为远程最新的备份版本
+	Meta *BackupMeta
+	// Unchanged 配置与远程最新的备份相同，跳过了上传
+	Unchanged bool
+}
+
+// BackupTargets 将备份目标按清单打包压缩，设置了口令时再加密，直接以流的方式上传到远程服务器，
+// 整个过程不产生本地临时文件。上传前会扫描疑似密钥并按策略处理。
+// 配置与远程最新的备份相同时跳过上传。已有备份时只上传相对上一次备份有变化的文件
+// （增量备份），指定全量备份、没有可用的上一次备份或连续增量备份次数达到上限时进行全量备份
+func BackupTargets(targets manifest.Targets, m *manifest.Manifest, opts BackupOptions) (*BackupResult, error) {
+	// 上传前扫描疑似密钥，脱敏会改变清单中文件的哈希，需要在比较之前进行。
+	// 同步记录中保留脱敏前的哈希
+	localHashes := fileHashes(m)
+	redacted, err := checkSecrets(targets, m, opts.Secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	store := opts.store()
+	recordsSync := store.RecordsSync() && !opts.Virtual
+	versions, err := store.List(m.Component)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) > 0 {
+		latest := &versions[len(versions)-1]
+
+		if !opts.Force {
+			// 恢复后合并过的文件在同步后没有修改时，按同步的备份内容比较，不重新上传
+			view, merged := m, false
+			if recordsSync {
+				view, merged = syncedView(m)
+			}
+			unchanged, err := isUnchanged(store, view, latest)
+			if err != nil {
+				fmt.Printf("⚠️ 无法判断配置是否有变化: %v\n", err)
+			}
+			if unchanged {
+				if opts.Tag != "" {
+					if latest, err = store.Tag(m.Component, latest.ID, opts.Tag); err != nil {
+						return nil, err
+					}
+				}
+				if recordsSync && !merged {
+					if err := saveSynced(withLocalHashes(m, localHashes)); err != nil {
+						fmt.Printf("⚠️ %v\n", err)
+					}
+				}
+				return &BackupResult{Meta: latest, Unchanged: true}, nil
+			}
+		}
+
+		if !opts.Full {
+			if err := prepareIncremental(store, m, versions); err != nil {
+				fmt.Printf("⚠️ %v，将进行全量备份\n", err)
+			}
+		}
+	}
+
+	passphrase := os.Getenv(config.BackupPassphraseEnv)
+
+	meta := NewBackupMeta(m)
+	meta.Encrypted = passphrase != ""
+	if opts.Tag != "" {
+		meta.Tags = []string{opts.Tag}
+	}
+
+	err = store.Upload(meta, func(w io.Writer) error {
+		if passphrase == "" {
+			return WriteArchive(w, targets, m, redacted)
+		}
+
+		ew, err := EncryptWriter(w, passphrase)
+		if err != nil {
+			return err
+		}
+		if err := WriteArchive(ew, targets, m, redacted); err != nil {
+			return err
+		}
+		if err := ew.Close(); err != nil {
+			return fmt.Errorf("加密失败: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if recordsSync {
+		if err := saveSynced(withLocalHashes(m, localHashes)); err != nil {
+			fmt.Printf("⚠️ %v\n", err)
+		}
+	}
+	return &BackupResult{Meta: meta}, nil
+}
+
+// isUnchanged 比较本地配置与远程备份的目录哈希，
+// 索引中没有记录目录哈希的旧备份会读取其清单计算
+func isUnchanged(store Store, m *manifest.Manifest, latest *BackupMeta) (bool, error) {
+	treeHash := latest.TreeHash
+	if treeHash == "" {
+		prev, _, err := FetchManifest(store, m.Component, latest.ID)
+		if err != nil {
+			return false, err
+		}
+		treeHash = prev.TreeHash()
+	}
+	return treeHash == m.TreeHash(), nil
+}
+
+// prepareIncremental 与上一次备份比较，将清单转换为增量备份的清单，
+// 应当进行全量备份时清单保持不变，返回的错误说明无法增量备份的原因
+func prepareIncremental(store Store, m *manifest.Manifest, versions []BackupMeta) error {
+	// 统计当前增量链上的备份次数，达到上限后重新进行全量备份
+	latest := versions[len(versions)-1]
+	base := latest.Base
+	if base == "" {
+		base = latest.ID
+	}
+	deltas := 0
+	for _, v := range versions {
+		if v.Base == base {
+			deltas++
+		}
+	}
+	if deltas >= config.MaxIncrementalBackups {
+		fmt.Printf("已连续进行 %d 次增量备份，本次进行全量备份\n", deltas)
+		return nil
+	}
+
+	prev, _, err := FetchManifest(store, m.Component, latest.ID)
+	if err != nil {
+		return fmt.Errorf("读取上一次备份的清单失败: %v", err)
+	}
+
+	stored := m.MarkUnchanged(prev, latest.ID)
+	m.Base = base
+	fmt.Printf("增量备份：%d 个文件有变化，基于全量备份 %s\n", stored, base)
+	return nil
+}
+
+// ApplyOptions 恢复选项
+type ApplyOptions struct {
+	// Version 恢复的备份版本号或标签，为空时恢复最新版本
+	Version string
+	// At 恢复该时间之前（含）最新的备份，不为零值时忽略 Version
+	At time.Time
+	// DryRun 只显示恢复会带来的文件变化，不修改本地文件
+	DryRun bool
+	// Conflict 本地有修改的文件的处理策略，为空时覆盖本地文件
+	Conflict ConflictPolicy
+	// Only 只恢复路径匹配这些 glob 模式的文件，为空时恢复全部文件
+	Only []string
+	// Target 恢复到该目录而不是配置所在的位置，用于查看备份内容
+	Target string
+	// Store 读取备份的存储位置，为空时从远程服务器读取
+	Store Store
+	// Virtual 恢复到组件临时创建的目录，由组件再按其中的内容恢复（如 Scoop 的应用列表），
+	// 不保存快照，不处理冲突，也不更新同步记录
+	Virtual bool
+}
+
+// RestoreTargets 从远程服务器以流的方式读取备份，解密后直接解压到备份目标在当前系统上的路径，
+// 增量备份会从其引用的各个版本中取回未变化的文件，还原出完整的配置。
+// 上一次同步后在本地修改过的文件按冲突处理策略处理，恢复完成后记录本次恢复的清单
+func RestoreTargets(component string, targets manifest.Targets, opts ApplyOptions) (*BackupMeta, error) {
+	m, meta, err := fetchRestoreManifest(component, opts)
+	if err != nil {
+		return nil, err
+	}
+	skipUnknownFiles(m, targets)
+
+	// 恢复到其他目录或临时目录时不处理冲突，也不记录同步的清单
+	live := opts.Target == "" && !opts.Virtual
+	store := opts.store()
+
+	var resolve ResolveFunc
+	var guard *renderGuard
+	if live {
+		guard = &renderGuard{synced: renderedHashes(m.Component)}
+	}
+	if live && opts.Conflict != "" && opts.Conflict != ConflictOverwrite {
+		conflicts, err := findConflicts(m, targets)
+		if err != nil {
+			return nil, err
+		}
+		cr := &conflictResolver{policy: opts.Conflict, conflicts: conflicts}
+		if len(conflicts) > 0 {
+			fmt.Printf("%d 个文件在本地有修改，按 %s 策略处理\n", len(conflicts), opts.Conflict)
+			resolve = cr.resolve
+		}
+		guard.cr = cr
+	}
+
+	resolve = keepRedacted(m, resolve)
+
+	for id, include := range m.Refs(meta.ID) {
+		_, err := store.Download(component, id, func(r io.Reader, meta *BackupMeta) error {
+			archive, err := openArchive(r, meta)
+			if err != nil {
+				return err
+			}
+			return ExtractArchive(archive, targets, include, resolve)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 按当前主机渲染模板文件，本地修改过的渲染结果同样按冲突处理策略处理
+	rendered, err := renderTemplates(m, targets, guard)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.RecordsSync() {
+		synced := withLocalHashes(m, redactedLocalHashes(m, targets))
+		for i := range synced.Files {
+			synced.Files[i].RenderedSHA256 = rendered[synced.Files[i].Path]
+		}
+		if err := saveSynced(syncedManifest(synced, opts.Only)); err != nil {
+			fmt.Printf("⚠️ %v\n", err)
+		}
+	}
+	return meta, nil
+}
+
+// keepRedacted 返回不用脱敏后的文件覆盖本地文件的 ResolveFunc，本地没有该文件时照常恢复并提示补充密钥
+func keepRedacted(m *manifest.Manifest, resolve ResolveFunc) ResolveFunc {
+	redacted := make(map[string]bool)
+	for _, f := range m.Files {
+		if f.Redacted {
+			redacted[f.Path] = true
+		}
+	}
+	if len(redacted) == 0 {
+		return resolve
+	}
+
+	return func(name, target string, header *tar.Header) (string, error) {
+		if redacted[name] {
+			if _, err := os.Stat(target); err == nil {
+				fmt.Printf("备份中的 %s 已脱敏，保留本地文件\n", name)
+				return "", nil
+			}
+			fmt.Printf("⚠️ 备份中的 %s 已脱敏，请手动补充其中的 %s\n", name, RedactedText)
+		}
+		if resolve != nil {
+			return resolve(name, target, header)
+		}
+		return target, nil
+	}
+}
+
+// fetchRestoreManifest 按版本号、标签或时间读取要恢复的备份的清单，指定了 Only 时只保留匹配的文件
+func fetchRestoreManifest(component string, opts ApplyOptions) (*manifest.Manifest, *BackupMeta, error) {
+	version := opts.Version
+	if !opts.At.IsZero() {
+		meta, err := findBackupAt(opts.store(), component, opts.At)
+		if err != nil {
+			return nil, nil, err
+		}
+		version = meta.ID
+	}
+
+	m, meta, err := FetchManifest(opts.store(), component, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(opts.Only) > 0 {
+		m.Filter(opts.Only)
+		if len(m.Files) == 0 {
+			return nil, nil, fmt.Errorf("备份 %s 中没有匹配 %s 的文件", meta.ID, strings.Join(opts.Only, ", "))
+		}
+	}
+	return m, meta, nil
+}
+
+// skipUnknownFiles 去掉清单中不属于任何备份目标的文件，
+// 如组件缩小备份范围之前的备份中的其他文件，这些文件不会被恢复
+func skipUnknownFiles(m *manifest.Manifest, targets manifest.Targets) {
+	files := m.Files[:0]
+	skipped := 0
+	for _, f := range m.Files {
+		// 早期版本可能备份了 keep-both 另存的副本，恢复时同样跳过
+		if strings.HasSuffix(f.Path, manifest.RemoteSuffix) {
+			continue
+		}
+		if _, err := targets.Resolve(f.Path); err != nil {
+			skipped++
+			continue
+		}
+		files = append(files, f)
+	}
+	m.Files = files
+	if skipped > 0 {
+		fmt.Printf("跳过备份中 %d 个不属于当前备份范围的文件\n", skipped)
+	}
+}
+
+// syncedManifest 返回恢复后应记录的清单，只恢复了部分文件时保留上一次同步记录中的其他文件
+func syncedManifest(m *manifest.Manifest, only []string) *manifest.Manifest {
+	if len(only) == 0 {
+		return m
+	}
+
+	prev, err := LoadSynced(m.Component)
+	if err != nil || prev == nil {
+		return m
+	}
+
+	merged := *m
+	merged.Files = append([]manifest.File(nil), m.Files...)
+	for _, f := range prev.Files {
+		if !manifest.Match(only, f.Path) {
+			merged.Files = append(merged.Files, f)
+		}
+	}
+	sort.Slice(merged.Files, func(i, j int) bool {
+		return merged.Files[i].Path < merged.Files[j].Path
+	})
+	return &merged
+}
+
+// PreviewRestore 比较远程备份与备份目标在当前系统上的文件，返回恢复会带来的变化，
+// 只下载有变化的文件内容，不修改本地文件
+func PreviewRestore(component string, targets manifest.Targets, opts ApplyOptions) (*BackupMeta, []FileChange, error) {
+	m, meta, err := fetchRestoreManifest(component, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	skipUnknownFiles(m, targets)
+
+	local, err := manifest.Build(component, targets, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(opts.Only) > 0 {
+		local.Filter(opts.Only)
+	}
+	localFiles := make(map[string]manifest.File, len(local.Files))
+	for _, f := range local.Files {
+		localFiles[f.Path] = f
+	}
+
+	// 找出新增和修改的文件
+	var changes []FileChange
+	changed := make(map[string]bool)
+	for _, f := range m.Files {
+		l, ok := localFiles[f.Path]
+		delete(localFiles, f.Path)
+		if ok && l.SHA256 == f.SHA256 && l.Mode == f.Mode {
+			continue
+		}
+
+		c := FileChange{Path: f.Path, Kind: ChangeAdded, RemoteMode: os.FileMode(f.Mode)}
+		if c.LocalPath, err = targets.Resolve(f.Path); err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			c.Kind = ChangeModified
+			c.LocalMode = os.FileMode(l.Mode)
+			if c.Local, err = os.ReadFile(c.LocalPath); err != nil {
+				return nil, nil, fmt.Errorf("读取本地文件失败: %v", err)
+			}
+		}
+		changes = append(changes, c)
+		changed[f.Path] = true
+	}
+
+	// 本地多出的文件
+	for _, l := range localFiles {
+		c := FileChange{Path: l.Path, Kind: ChangeDeleted, LocalMode: os.FileMode(l.Mode)}
+		if c.LocalPath, err = targets.Resolve(l.Path); err != nil {
+			return nil, nil, err
+		}
+		changes = append(changes, c)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	// 下载有变化的文件和全部模板的内容，增量备份需要从引用的各个版本中读取
+	remote := make(map[string][]byte)
+	for id, include := range m.Refs(meta.ID) {
+		for name := range include {
+			if !changed[name] && !IsTemplate(name) {
+				delete(include, name)
+			}
+		}
+		if len(include) == 0 {
+			continue
+		}
+
+		_, err := opts.store().Download(component, id, func(r io.Reader, meta *BackupMeta) error {
+			archive, err := openArchive(r, meta)
+			if err != nil {
+				return err
+			}
+			files, err := ReadArchiveFiles(archive, include)
+			if err != nil {
+				return err
+			}
+			for name, data := range files {
+				remote[name] = data
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	for i := range changes {
+		changes[i].Remote = remote[changes[i].Path]
+	}
+
+	// 模板按当前主机渲染后与本地文件比较
+	changes, err = previewTemplates(m, targets, remote, changes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return meta, changes, nil
+}
+
+// previewTemplates 将模板的渲染结果与本地文件比较，有差异时加入变化列表
+func previewTemplates(m *manifest.Manifest, targets manifest.Targets, remote map[string][]byte, changes []FileChange) ([]FileChange, error) {
+	var data *TemplateData
+	for _, f := range m.Files {
+		if !IsTemplate(f.Path) {
+			continue
+		}
+		if data == nil {
+			var err error
+			if data, err = NewTemplateData(m.Component); err != nil {
+				return nil, err
+			}
+		}
+
+		output, err := RenderTemplate(f.Path, remote[f.Path], data)
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimSuffix(f.Path, manifest.TemplateExt)
+		path, err := targets.Resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		c := FileChange{Path: name, Kind: ChangeAdded, LocalPath: path, Remote: output, RemoteMode: os.FileMode(f.Mode)}
+		if info, err := os.Stat(path); err == nil {
+			if c.Local, err = os.ReadFile(path); err != nil {
+				return nil, fmt.Errorf("读取本地文件失败: %v", err)
+			}
+			if bytes.Equal(c.Local, output) {
+				c.Kind = ""
+			} else {
+				c.Kind = ChangeModified
+			}
+			c.LocalMode = info.Mode().Perm()
+			c.RemoteMode = c.LocalMode
+		}
+
+		// 本地没有模板时渲染结果会被当作本地多出的文件，以渲染结果为准
+		filtered := changes[:0]
+		for _, existing := range changes {
+			if existing.Path != name {
+				filtered = append(filtered, existing)
+			}
+		}
+		changes = filtered
+		if c.Kind != "" {
+			changes = append(changes, c)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes, nil
+}
+
+// FetchManifest 读取存储中备份的清单，只下载归档开头的清单部分
+func FetchManifest(store Store, component, version string) (*manifest.Manifest, *BackupMeta, error) {
+	var m *manifest.Manifest
+	meta, err := store.Download(component, version, func(r io.Reader, meta *BackupMeta) error {
+		archive, err := openArchive(r, meta)
+		if err != nil {
+			return err
+		}
+		m, err = ReadManifest(archive)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, meta, nil
+}
+
+// openArchive 按备份元数据返回归档的读取流，加密的备份会先解密
+func openArchive(r io.Reader, meta *BackupMeta) (io.Reader, error) {
+	if meta.Format != FormatTarGz {
+		return nil, fmt.Errorf("不支持的归档格式: %s", meta.Format)
+	}
+	if !meta.Encrypted {
+		return r, nil
+	}
+
+	passphrase := os.Getenv(config.BackupPassphraseEnv)
+	if passphrase == "" {
+		return nil, fmt.Errorf("备份已加密，请通过环境变量 %s 提供口令", config.BackupPassphraseEnv)
+	}
+	return DecryptReader(r, passphrase)
+}
@@ -1,19 +1,96 @@
 package utils
 
 import (
+	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
-	"runtime"
-	"strings"
+	"sort"
+	"time"
 
 	"qs-tools/internal/config"
+	"qs-tools/internal/manifest"
 
 	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
 )
 
+// ErrRemoteUnavailable 无法连接远程服务器，可以通过 errors.Is 判断
+var ErrRemoteUnavailable = errors.New("无法连接远程服务器")
+
+// unavailableError 连接远程服务器失败的错误，保留原始的错误信息
+type unavailableError struct {
+	msg string
+}
+
+func (e *unavailableError) Error() string        { return e.msg }
+func (e *unavailableError) Is(target error) bool { return target == ErrRemoteUnavailable }
+
+// BackupMeta 备份元数据，记录在远程服务器上组件目录的 index.json 中
+type BackupMeta struct {
+	// ID 备份版本号，按备份时间生成，同一秒内的多次备份加上 -2、-3 等序号
+	ID string `json:"id"`
+	// Component 组件名称
+	Component string `json:"component"`
+	// Format 备份文件的归档格式
+	Format string `json:"format"`
+	// Hostname 创建备份的主机名
+	Hostname string `json:"hostname"`
+	// OS 创建备份的操作系统
+	OS string `json:"os"`
+	// Base 增量备份所基于的全量备份版本，全量备份为空
+	Base string `json:"base,omitempty"`
+	// TreeHash 备份时配置目录的哈希，用于判断配置是否有变化
+	TreeHash string `json:"tree_hash,omitempty"`
+	// Encrypted 备份是否已加密
+	Encrypted bool `json:"encrypted,omitempty"`
+	// Tags 备份的标签，同一组件中每个标签只属于一个版本
+	Tags []string `json:"tags,omitempty"`
+	// CreatedAt 备份时间
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewBackupMeta 根据备份清单生成新版本的元数据
+func NewBackupMeta(m *manifest.Manifest) *BackupMeta {
+	return &BackupMeta{
+		ID:        m.CreatedAt.Format("20060102-150405"),
+		Component: m.Component,
+		Format:    FormatTarGz,
+		Hostname:  m.Hostname,
+		OS:        m.OS,
+		Base:      m.Base,
+		TreeHash:  m.TreeHash(),
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+// backupIndex 组件的备份版本索引，按备份时间升序排列
+type backupIndex struct {
+	Versions []BackupMeta `json:"versions"`
+}
+
+// remoteBufferSize 上传和下载时的缓冲区大小
+const remoteBufferSize = 256 * 1024
+
+// remoteComponentDir 返回组件在远程服务器上的备份目录
+func remoteComponentDir(component string) string {
+	return path.Join(filepath.ToSlash(config.DefaultServerPath), component)
+}
+
+// remoteBackupPath 返回备份文件在远程服务器上的路径，文件名与操作系统和归档格式无关
+func remoteBackupPath(component, id string) string {
+	return path.Join(remoteComponentDir(component), id)
+}
+
+// remoteIndexPath 返回组件备份索引在远程服务器上的路径
+func remoteIndexPath(component string) string {
+	return path.Join(remoteComponentDir(component), "index.json")
+}
+
 // 创建 SSH 客户端配置
 func createSSHConfig() *ssh.ClientConfig {
 	return &ssh.ClientConfig{
@@ -32,7 +109,7 @@ func connectSFTP() (*sftp.Client, *ssh.Client, error) {
 	// 连接到 SSH 服务器
 	sshClient, err := ssh.Dial("tcp", fmt.Sprintf("%s:22", config.DefaultServerIP), sshConfig)
 	if err != nil {
-		return nil, nil, fmt.Errorf("连接 SSH 服务器失败: %v", err)
+		return nil, nil, &unavailableError{fmt.Sprintf("连接 SSH 服务器失败: %v", err)}
 	}
 
 	// 创建 SFTP 客户端
@@ -45,53 +122,296 @@ func connectSFTP() (*sftp.Client, *ssh.Client, error) {
 	return sftpClient, sshClient, nil
 }
 
-// DownloadFromRemote 从远程服务器下载文件
-func DownloadFromRemote(component, localFile string) error {
+// sftpStore 通过 SFTP 读写远程服务器上的备份，每个操作单独建立连接
+type sftpStore struct{}
+
+// RemoteStore 返回远程服务器上的备份存储
+func RemoteStore() Store {
+	return sftpStore{}
+}
+
+// RecordsSync 备份到远程服务器或从远程服务器恢复后更新同步记录
+func (sftpStore) RecordsSync() bool {
+	return true
+}
+
+// Download 从远程服务器读取指定版本的备份，version 为空时读取最新版本，
+// 备份内容以流的方式交给 read 处理，不落地到本地文件
+func (sftpStore) Download(component, version string, read func(r io.Reader, meta *BackupMeta) error) (*BackupMeta, error) {
 	// 连接到 SFTP 服务器
 	sftpClient, sshClient, err := connectSFTP()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer sshClient.Close()
 	defer sftpClient.Close()
 
-	// 构建远程文件路径（直接从上传目录下获取）
-	remoteFile := filepath.Join(config.DefaultServerPath, fmt.Sprintf("%s_backup", component))
-	if runtime.GOOS == "windows" {
-		remoteFile += ".zip"
-	} else {
-		remoteFile += ".tar.gz"
+	// 查找备份版本
+	index, err := readRemoteIndex(sftpClient, component)
+	if err != nil {
+		return nil, err
 	}
-	remoteFile = filepath.ToSlash(remoteFile)
+	meta, err := index.find(component, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// 构建远程文件路径
+	remoteFile := remoteBackupPath(component, meta.ID)
 
 	fmt.Printf("正在从 %s 下载文件...\n", remoteFile)
 
 	// 打开远程文件
 	srcFile, err := sftpClient.Open(remoteFile)
 	if err != nil {
-		return fmt.Errorf("打开远程文件失败: %v", err)
+		return nil, fmt.Errorf("打开远程文件失败: %v", err)
 	}
 	defer srcFile.Close()
 
-	// 创建本地文件
-	dstFile, err := os.Create(localFile)
+	if err := read(bufio.NewReaderSize(srcFile, remoteBufferSize), meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// List 列出组件在远程服务器上的所有备份版本
+func (sftpStore) List(component string) ([]BackupMeta, error) {
+	sftpClient, sshClient, err := connectSFTP()
 	if err != nil {
-		return fmt.Errorf("创建本地文件失败: %v", err)
+		return nil, err
 	}
-	defer dstFile.Close()
+	defer sshClient.Close()
+	defer sftpClient.Close()
 
-	// 复制文件内容
-	if _, err := io.Copy(dstFile, srcFile); err != nil {
-		return fmt.Errorf("下载文件失败: %v", err)
+	index, err := readRemoteIndex(sftpClient, component)
+	if err != nil {
+		return nil, err
 	}
+	return index.Versions, nil
+}
 
+// ListComponents 列出远程服务器上有备份的组件名称，按名称排序
+func (sftpStore) ListComponents() ([]string, error) {
+	sftpClient, sshClient, err := connectSFTP()
+	if err != nil {
+		return nil, err
+	}
+	defer sshClient.Close()
+	defer sftpClient.Close()
+
+	entries, err := sftpClient.ReadDir(remoteComponentDir(""))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取远程备份目录失败: %v", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		// 只有目录中有备份索引的才是组件的备份
+		if _, err := sftpClient.Stat(remoteIndexPath(e.Name())); err == nil {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// find 按版本号或标签查找备份版本，version 为空时返回最新版本
+func (index *backupIndex) find(component, version string) (*BackupMeta, error) {
+	if len(index.Versions) == 0 {
+		return nil, fmt.Errorf("远程服务器上没有 %s 的备份", component)
+	}
+	if version == "" {
+		return &index.Versions[len(index.Versions)-1], nil
+	}
+	for i := range index.Versions {
+		if index.Versions[i].ID == version {
+			return &index.Versions[i], nil
+		}
+	}
+	for i := len(index.Versions) - 1; i >= 0; i-- {
+		if index.Versions[i].HasTag(version) {
+			return &index.Versions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("未找到 %s 的备份版本: %s", component, version)
+}
+
+// findAt 返回指定时间之前（含）最新的备份版本
+func (index *backupIndex) findAt(component string, at time.Time) (*BackupMeta, error) {
+	for i := len(index.Versions) - 1; i >= 0; i-- {
+		if !index.Versions[i].CreatedAt.After(at) {
+			return &index.Versions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s 没有 %s 之前的备份", component, at.Format("2006-01-02 15:04:05"))
+}
+
+// HasTag 判断备份是否有指定标签
+func (meta *BackupMeta) HasTag(tag string) bool {
+	for _, t := range meta.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Tag 为组件在远程服务器上的备份版本添加标签，标签已属于其他版本时移到该版本
+func (sftpStore) Tag(component, version, tag string) (*BackupMeta, error) {
+	sftpClient, sshClient, err := connectSFTP()
+	if err != nil {
+		return nil, err
+	}
+	defer sshClient.Close()
+	defer sftpClient.Close()
+
+	var id string
+	index, err := updateRemoteIndex(sftpClient, component, func(index *backupIndex) error {
+		meta, err := index.find(component, version)
+		if err != nil {
+			return err
+		}
+		id = meta.ID
+		index.tag(id, tag)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return index.find(component, id)
+}
+
+// tag 为版本 id 添加标签，并从其他版本中移除该标签
+func (index *backupIndex) tag(id, tag string) {
+	for i := range index.Versions {
+		v := &index.Versions[i]
+		tags := v.Tags[:0]
+		for _, t := range v.Tags {
+			if t != tag {
+				tags = append(tags, t)
+			}
+		}
+		if v.ID == id {
+			tags = append(tags, tag)
+		}
+		if len(tags) == 0 {
+			tags = nil
+		}
+		v.Tags = tags
+	}
+}
+
+// uniqueID 返回尚未使用的版本号，同一秒内多次备份时加上序号。
+// exists 检查存储中是否已有该版本的文件，为 nil 时只检查索引
+func (index *backupIndex) uniqueID(id string, exists func(id string) bool) string {
+	used := make(map[string]bool, len(index.Versions))
+	for _, v := range index.Versions {
+		used[v.ID] = true
+	}
+	unique := id
+	for i := 2; used[unique] || (exists != nil && exists(unique)); i++ {
+		unique = fmt.Sprintf("%s-%d", id, i)
+	}
+	return unique
+}
+
+// ParseBackupTime 解析命令行中的时间，支持日期（表示当天结束）、日期时间和备份版本号格式，按本地时区解析
+func ParseBackupTime(s string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{
+		"2006-01-02 15:04",
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02T15:04:05",
+		"20060102-150405",
+	} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法识别的时间: %s（如 2026-10-01 或 \"2026-10-01 18:30\"）", s)
+}
+
+// readRemoteIndex 读取远程服务器上的组件备份索引，索引不存在时返回空索引
+func readRemoteIndex(client *sftp.Client, component string) (*backupIndex, error) {
+	index := &backupIndex{}
+
+	file, err := client.Open(remoteIndexPath(component))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+		return nil, fmt.Errorf("打开备份索引失败: %v", err)
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(index); err != nil {
+		return nil, fmt.Errorf("解析备份索引失败: %v", err)
+	}
+	return index, nil
+}
+
+// updateRemoteIndex 重新读取远程服务器上最新的组件备份索引，经 update 修改后写回，
+// 尽量缩短读取和写入之间的间隔，保留其他主机在此期间添加的版本
+func updateRemoteIndex(client *sftp.Client, component string, update func(index *backupIndex) error) (*backupIndex, error) {
+	index, err := readRemoteIndex(client, component)
+	if err != nil {
+		return nil, err
+	}
+	if err := update(index); err != nil {
+		return nil, err
+	}
+	if err := writeRemoteIndex(client, component, index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// writeRemoteIndex 将组件备份索引写入远程服务器。先写入 index.json.tmp 再重命名，
+// 写入中断时不会留下不完整的索引
+func writeRemoteIndex(client *sftp.Client, component string, index *backupIndex) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("生成备份索引失败: %v", err)
+	}
+
+	indexPath := remoteIndexPath(component)
+	tmp := indexPath + ".tmp"
+	file, err := client.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("创建备份索引失败: %v", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		client.Remove(tmp)
+		return fmt.Errorf("写入备份索引失败: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		client.Remove(tmp)
+		return fmt.Errorf("写入备份索引失败: %v", err)
+	}
+	if err := client.PosixRename(tmp, indexPath); err != nil {
+		client.Remove(tmp)
+		return fmt.Errorf("保存备份索引失败: %v", err)
+	}
 	return nil
 }
 
 // 检查并创建远程目录
-func ensureRemoteDir(client *sftp.Client, path string) error {
+func ensureRemoteDir(client *sftp.Client, dir string) error {
 	// 先检查父目录
-	parentDir := filepath.Dir(path)
+	parentDir := path.Dir(dir)
 	parentInfo, err := client.Stat(parentDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -104,11 +424,11 @@ func ensureRemoteDir(client *sftp.Client, path string) error {
 	}
 
 	// 检查目标路径
-	info, err := client.Stat(path)
+	info, err := client.Stat(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// 目录不存在，尝试创建
-			if err := client.MkdirAll(path); err != nil {
+			if err := client.MkdirAll(dir); err != nil {
 				return fmt.Errorf("创建目录失败: %v", err)
 			}
 			return nil
@@ -118,14 +438,15 @@ func ensureRemoteDir(client *sftp.Client, path string) error {
 
 	// 路径存在，确保是目录
 	if !info.IsDir() {
-		return fmt.Errorf("目标路径已存在但不是目录: %s", path)
+		return fmt.Errorf("目标路径已存在但不是目录: %s", dir)
 	}
 
 	return nil
 }
 
-// UploadToRemote 上传文件到远程服务器
-func UploadToRemote(component, localFile string) error {
+// Upload 将 write 写出的数据直接上传为组件的一个新版本，
+// 任一环节失败都会删除不完整的远程文件，上传成功后才更新备份索引
+func (sftpStore) Upload(meta *BackupMeta, write func(w io.Writer) error) error {
 	// 连接到 SFTP 服务器
 	sftpClient, sshClient, err := connectSFTP()
 	if err != nil {
@@ -143,45 +464,63 @@ func UploadToRemote(component, localFile string) error {
 		return fmt.Errorf("上传路径不是目录: %s", config.DefaultServerPath)
 	}
 
-	// 构建远程文件路径（直接放在上传目录下）
-	remoteFile := filepath.Join(config.DefaultServerPath, fmt.Sprintf("%s_backup", component))
-	if runtime.GOOS == "windows" {
-		remoteFile += ".zip"
-	} else {
-		remoteFile += ".tar.gz"
+	// 确保组件备份目录存在
+	if err := ensureRemoteDir(sftpClient, remoteComponentDir(meta.Component)); err != nil {
+		return err
 	}
-	remoteFile = filepath.ToSlash(remoteFile)
-
-	fmt.Printf("正在上传到 %s...\n", remoteFile)
 
-	// 打开本地文件
-	srcFile, err := os.Open(localFile)
+	index, err := readRemoteIndex(sftpClient, meta.Component)
 	if err != nil {
-		return fmt.Errorf("打开本地文件失败: %v", err)
+		return err
 	}
-	defer srcFile.Close()
+
+	// 同一秒内多次备份时加上序号，避免覆盖已有的备份
+	meta.ID = index.uniqueID(meta.ID, func(id string) bool {
+		_, err := sftpClient.Stat(remoteBackupPath(meta.Component, id))
+		return err == nil
+	})
+
+	// 构建远程文件路径
+	remoteFile := remoteBackupPath(meta.Component, meta.ID)
+
+	fmt.Printf("正在上传到 %s...\n", remoteFile)
 
 	// 创建远程文件
 	dstFile, err := sftpClient.Create(remoteFile)
 	if err != nil {
 		return fmt.Errorf("创建远程文件失败: %v", err)
 	}
-	defer dstFile.Close()
 
-	// 复制文件内容
-	if _, err := io.Copy(dstFile, srcFile); err != nil {
+	bw := bufio.NewWriterSize(dstFile, remoteBufferSize)
+	if err := write(bw); err != nil {
+		dstFile.Close()
+		sftpClient.Remove(remoteFile)
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		dstFile.Close()
+		sftpClient.Remove(remoteFile)
+		return fmt.Errorf("上传文件失败: %v", err)
+	}
+	if err := dstFile.Close(); err != nil {
+		sftpClient.Remove(remoteFile)
 		return fmt.Errorf("上传文件失败: %v", err)
 	}
 
-	return nil
+	// 备份文件上传完成后再更新索引，标签从其他版本移到新版本
+	_, err = updateRemoteIndex(sftpClient, meta.Component, func(index *backupIndex) error {
+		index.add(meta)
+		return nil
+	})
+	return err
 }
 
-// getRemoteFileName 根据本地文件名获取远程文件名
-func getRemoteFileName(localFile string) string {
-	base := strings.TrimSuffix(localFile, ".tar.gz")
-	base = strings.TrimSuffix(base, ".zip")
-	if runtime.GOOS == "windows" {
-		return base + ".zip"
+// add 将新版本加入索引，标签从其他版本移到新版本
+func (index *backupIndex) add(meta *BackupMeta) {
+	v := *meta
+	v.Tags = nil
+	index.Versions = append(index.Versions, v)
+	for _, tag := range meta.Tags {
+		index.tag(meta.ID, tag)
 	}
-	return base + ".tar.gz"
 }
@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+
+	"qs-tools/internal/manifest"
+)
+
+// SecretPolicy 备份时发现疑似密钥的处理策略
+type SecretPolicy string
+
+const (
+	// SecretRefuse 发现疑似密钥时取消备份
+	SecretRefuse SecretPolicy = "refuse"
+	// SecretRedact 将疑似密钥替换为 RedactedText 后再上传
+	SecretRedact SecretPolicy = "redact"
+	// SecretWarn 只给出警告，照常上传
+	SecretWarn SecretPolicy = "warn"
+)
+
+// SecretPolicies 全部密钥处理策略
+var SecretPolicies = []SecretPolicy{SecretRefuse, SecretRedact, SecretWarn}
+
+// RedactedText 脱敏后替换密钥的文本
+const RedactedText = "[REDACTED]"
+
+// maxScanSize 超过该大小的文件不扫描
+const maxScanSize = 1 << 20
+
+// ParseSecretPolicy 解析密钥处理策略，为空时使用 SecretRefuse
+func ParseSecretPolicy(s string) (SecretPolicy, error) {
+	if s == "" {
+		return SecretRefuse, nil
+	}
+	for _, p := range SecretPolicies {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("不支持的密钥处理策略: %s（可选 %s）", s, strings.Join(SecretPolicyNames(), "、"))
+}
+
+// SecretPolicyNames 返回全部密钥处理策略的名称
+func SecretPolicyNames() []string {
+	names := make([]string, 0, len(SecretPolicies))
+	for _, p := range SecretPolicies {
+		names = append(names, string(p))
+	}
+	return names
+}
+
+// SecretFinding 扫描发现的疑似密钥
+type SecretFinding struct {
+	// Path 归档中的路径
+	Path string
+	// Line 所在行号，从 1 开始
+	Line int
+	// Kind 密钥类型
+	Kind string
+	// Preview 打码后的密钥，用于提示
+	Preview string
+}
+
+// secretRule 密钥匹配规则，group 为密钥所在的分组，entropy 表示还需要检查随机性
+type secretRule struct {
+	kind    string
+	re      *regexp.Regexp
+	group   int
+	entropy bool
+}
+
+var secretRules = []secretRule{
+	{kind: "AWS Access Key", re: regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`), group: 1},
+	{kind: "AWS Secret Key", re: regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=\s]\s*["']?([A-Za-z0-9/+=]{40})`), group: 1},
+	{kind: "GitHub Token", re: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,})\b`), group: 1},
+	{kind: "GitHub Token", re: regexp.MustCompile(`\b(github_pat_[A-Za-z0-9_]{22,})\b`), group: 1},
+	{kind: "私钥", re: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY( BLOCK)?-----`)},
+	// 只匹配以 = 或 : 赋值的情况，空白分隔时误报太多（如 fish 的 set -gx AUTHOR_EMAIL、git 的 author）
+	{
+		kind:    "疑似密钥赋值",
+		re:      regexp.MustCompile(`(?i)[A-Za-z0-9_]*(?:token|secret|passw(?:or)?d|api_?key|access_?key)[A-Za-z0-9_]*["']?\s*[:=]\s*["']?([A-Za-z0-9_\-+/=.\\]{16,})`),
+		group:   1,
+		entropy: true,
+	},
+}
+
+// minSecretEntropy 疑似密钥赋值中的值每个字符的最小信息熵（比特），低于该值的视为普通文本
+const minSecretEntropy = 3.5
+
+// ScanSecrets 扫描文件内容中的疑似密钥，返回发现的密钥和脱敏后的内容。
+// 私钥会整块替换，其他密钥只替换密钥本身
+func ScanSecrets(name string, content []byte) ([]SecretFinding, []byte) {
+	if len(content) > maxScanSize || bytes.IndexByte(content, 0) >= 0 {
+		return nil, content
+	}
+
+	var findings []SecretFinding
+	var out []string
+	inKey := false
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		if inKey {
+			if strings.Contains(line, "-----END ") {
+				inKey = false
+				out = append(out, line)
+			}
+			continue
+		}
+
+		redacted := line
+		for _, rule := range secretRules {
+			for _, match := range rule.re.FindAllStringSubmatchIndex(line, -1) {
+				if rule.group == 0 {
+					// 私钥：保留开头和结尾，替换中间的内容
+					findings = append(findings, SecretFinding{Path: name, Line: i + 1, Kind: rule.kind, Preview: line[match[0]:match[1]]})
+					if !strings.Contains(line, "-----END ") {
+						inKey = true
+						redacted = line + "\n" + RedactedText
+					}
+					continue
+				}
+
+				secret := line[match[2*rule.group]:match[2*rule.group+1]]
+				if rule.entropy && !isHighEntropy(secret) {
+					continue
+				}
+				if strings.Contains(redacted, secret) {
+					findings = append(findings, SecretFinding{Path: name, Line: i + 1, Kind: rule.kind, Preview: maskSecret(secret)})
+					redacted = strings.ReplaceAll(redacted, secret, RedactedText)
+				}
+			}
+		}
+		out = append(out, redacted)
+	}
+
+	if len(findings) == 0 {
+		return nil, content
+	}
+	return findings, []byte(strings.Join(out, "\n"))
+}
+
+// isHighEntropy 判断字符串的香农熵是否足够高，用于区分随机生成的密钥和普通单词、路径
+func isHighEntropy(s string) bool {
+	if strings.HasPrefix(s, "$") || strings.HasPrefix(s, "/") || strings.HasPrefix(s, "~") {
+		return false
+	}
+
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	entropy := 0.0
+	n := float64(len([]rune(s)))
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= minSecretEntropy
+}
+
+// maskSecret 只保留密钥的开头和结尾
+func maskSecret(s string) string {
+	if len(s) <= 8 {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:4] + strings.Repeat("*", 8) + s[len(s)-4:]
+}
+
+// checkSecrets 扫描清单中的文件，按策略处理发现的疑似密钥。
+// 脱敏时更新清单中文件的哈希和大小，返回需要以脱敏内容写入归档的文件
+func checkSecrets(targets manifest.Targets, m *manifest.Manifest, policy SecretPolicy) (map[string][]byte, error) {
+	var findings []SecretFinding
+	redacted := make(map[string][]byte)
+	for i := range m.Files {
+		f := &m.Files[i]
+		if f.Size > maxScanSize {
+			continue
+		}
+
+		path, err := targets.Resolve(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取文件失败: %v", err)
+		}
+
+		found, output := ScanSecrets(f.Path, content)
+		if len(found) == 0 {
+			continue
+		}
+		findings = append(findings, found...)
+
+		if policy == SecretRedact {
+			sum := sha256.Sum256(output)
+			f.SHA256 = hex.EncodeToString(sum[:])
+			f.Size = int64(len(output))
+			f.Redacted = true
+			redacted[f.Path] = output
+		}
+	}
+	if len(findings) == 0 {
+		return nil, nil
+	}
+
+	fmt.Printf("⚠️ 发现 %d 处疑似密钥：\n", len(findings))
+	for _, f := range findings {
+		fmt.Printf("  %s:%d  %s  %s\n", f.Path, f.Line, f.Kind, f.Preview)
+	}
+
+	switch policy {
+	case SecretRedact:
+		fmt.Printf("已在备份中将 %d 个文件的密钥替换为 %s\n", len(redacted), RedactedText)
+		return redacted, nil
+	case SecretWarn:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("备份中包含疑似密钥，已取消备份（可以通过 --secrets redact 脱敏后上传，或 --secrets warn 忽略）")
+	}
+}
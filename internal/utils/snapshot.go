@@ -0,0 +1,234 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"qs-tools/internal/config"
+	"qs-tools/internal/manifest"
+)
+
+// snapshotExt 快照文件的扩展名，快照与远程备份使用相同的归档格式
+const snapshotExt = "." + FormatTarGz
+
+// Snapshot 恢复前在本地保存的配置快照
+type Snapshot struct {
+	ID        string
+	Component string
+	// Path 快照文件路径
+	Path      string
+	CreatedAt time.Time
+	// Files 快照中的文件数量
+	Files int
+}
+
+// snapshotDir 返回组件的快照目录 <状态目录>/snapshots/<component>
+func snapshotDir(component string) (string, error) {
+	stateDir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "snapshots", component), nil
+}
+
+// CreateSnapshot 将备份目标在当前系统上的文件保存为本地快照。
+// 本地没有文件时同样创建没有文件的快照，回滚到该快照会删除之后恢复的文件。超过保留数量的旧快照会被删除
+func CreateSnapshot(component string, targets manifest.Targets) (*Snapshot, error) {
+	m, err := manifest.Build(component, targets, "")
+	if err != nil {
+		return nil, fmt.Errorf("创建快照失败: %v", err)
+	}
+
+	dir, err := snapshotDir(component)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("创建快照目录失败: %v", err)
+	}
+
+	// 同一秒内多次创建时加上序号
+	id := m.CreatedAt.Format("20060102-150405")
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(dir, id+snapshotExt)); os.IsNotExist(err) {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", m.CreatedAt.Format("20060102-150405"), i)
+	}
+	path := filepath.Join(dir, id+snapshotExt)
+
+	// 先写入临时文件，完成后再重命名，避免留下不完整的快照
+	tmp, err := os.CreateTemp(dir, ".snapshot-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建快照失败: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := WriteArchive(tmp, targets, m, nil); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("创建快照失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("创建快照失败: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("创建快照失败: %v", err)
+	}
+
+	if err := rotateSnapshots(component); err != nil {
+		fmt.Printf("⚠️ 清理旧快照失败: %v\n", err)
+	}
+
+	return &Snapshot{
+		ID:        id,
+		Component: component,
+		Path:      path,
+		CreatedAt: m.CreatedAt,
+		Files:     len(m.Files),
+	}, nil
+}
+
+// rotateSnapshots 只保留最新的 config.MaxSnapshots 个快照
+func rotateSnapshots(component string) error {
+	snapshots, err := ListSnapshots(component)
+	if err != nil {
+		return err
+	}
+	for len(snapshots) > config.MaxSnapshots {
+		if err := os.Remove(snapshots[0].Path); err != nil {
+			return err
+		}
+		snapshots = snapshots[1:]
+	}
+	return nil
+}
+
+// ListSnapshots 返回组件的全部本地快照，按创建时间从旧到新排序
+func ListSnapshots(component string) ([]Snapshot, error) {
+	dir, err := snapshotDir(component)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取快照目录失败: %v", err)
+	}
+
+	var snapshots []Snapshot
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), snapshotExt) {
+			continue
+		}
+
+		s := Snapshot{
+			ID:        strings.TrimSuffix(e.Name(), snapshotExt),
+			Component: component,
+			Path:      filepath.Join(dir, e.Name()),
+		}
+		m, err := readSnapshotManifest(s.Path)
+		if err != nil {
+			fmt.Printf("⚠️ 忽略无法读取的快照 %s: %v\n", s.ID, err)
+			continue
+		}
+		s.CreatedAt = m.CreatedAt
+		s.Files = len(m.Files)
+		snapshots = append(snapshots, s)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		if !snapshots[i].CreatedAt.Equal(snapshots[j].CreatedAt) {
+			return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+		}
+		return snapshots[i].ID < snapshots[j].ID
+	})
+	return snapshots, nil
+}
+
+// RestoreSnapshot 将本地快照恢复到备份目标在当前系统上的路径，id 为空时恢复最新的快照。
+// 恢复后目标中不属于快照的文件会被删除，使配置与创建快照时完全一致
+func RestoreSnapshot(component, id string, targets manifest.Targets) (*Snapshot, error) {
+	snapshots, err := ListSnapshots(component)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("%s 没有本地快照", component)
+	}
+
+	s := &snapshots[len(snapshots)-1]
+	if id != "" {
+		s = nil
+		for i := range snapshots {
+			if snapshots[i].ID == id {
+				s = &snapshots[i]
+				break
+			}
+		}
+		if s == nil {
+			return nil, fmt.Errorf("快照不存在: %s", id)
+		}
+	}
+
+	m, err := readSnapshotManifest(s.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("读取快照失败: %v", err)
+	}
+	defer file.Close()
+
+	if err := ExtractArchive(file, targets, nil, nil); err != nil {
+		return nil, err
+	}
+
+	// 删除快照之后新增的文件
+	current, err := manifest.Build(component, targets, "")
+	if err != nil {
+		return nil, err
+	}
+	keep := make(map[string]bool, len(m.Files))
+	for _, f := range m.Files {
+		keep[f.Path] = true
+	}
+	for _, f := range current.Files {
+		if keep[f.Path] {
+			continue
+		}
+		path, err := targets.Resolve(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("删除文件失败: %v", err)
+		}
+		// 快照之后恢复的模板，其渲染结果不在清单中，同样删除
+		if IsTemplate(f.Path) && !keep[strings.TrimSuffix(f.Path, manifest.TemplateExt)] {
+			if err := os.Remove(strings.TrimSuffix(path, manifest.TemplateExt)); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("删除文件失败: %v", err)
+			}
+		}
+	}
+
+	return s, nil
+}
+
+// readSnapshotManifest 读取快照的清单
+func readSnapshotManifest(path string) (*manifest.Manifest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取快照失败: %v", err)
+	}
+	defer file.Close()
+	return ReadManifest(file)
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"io"
+	"time"
+)
+
+// Store 备份的存储位置。默认为远程服务器（RemoteStore），导出和导入离线包时为离线包（*Bundle），
+// 通过 BackupOptions.Store 和 ApplyOptions.Store 指定，备份和恢复的流程与存储位置无关
+type Store interface {
+	// List 列出组件的所有备份版本，按备份时间升序排列
+	List(component string) ([]BackupMeta, error)
+	// ListComponents 列出有备份的组件名称，按名称排序
+	ListComponents() ([]string, error)
+	// Download 读取指定版本号或标签的备份，version 为空时读取最新版本，
+	// 备份内容以流的方式交给 read 处理
+	Download(component, version string, read func(r io.Reader, meta *BackupMeta) error) (*BackupMeta, error)
+	// Upload 将 write 写出的数据保存为组件的一个新版本
+	Upload(meta *BackupMeta, write func(w io.Writer) error) error
+	// Tag 为组件的备份版本添加标签，标签已属于其他版本时移到该版本
+	Tag(component, version, tag string) (*BackupMeta, error)
+	// RecordsSync 在该存储上备份或恢复后是否更新本地的同步记录。
+	// 同步记录只描述本地与远程服务器的关系，离线包的导出和导入都不更新
+	RecordsSync() bool
+}
+
+// store 返回备份使用的存储，未指定时为远程服务器
+func (opts BackupOptions) store() Store {
+	if opts.Store == nil {
+		return RemoteStore()
+	}
+	return opts.Store
+}
+
+// store 返回恢复使用的存储，未指定时为远程服务器
+func (opts ApplyOptions) store() Store {
+	if opts.Store == nil {
+		return RemoteStore()
+	}
+	return opts.Store
+}
+
+// RecordsSync 恢复后是否更新本地的同步记录：只有从远程服务器恢复到备份目标原来的位置时才更新
+func (opts ApplyOptions) RecordsSync() bool {
+	return opts.Target == "" && !opts.Virtual && opts.store().RecordsSync()
+}
+
+// findBackupAt 返回组件在指定时间之前（含）最新的备份版本
+func findBackupAt(store Store, component string, at time.Time) (*BackupMeta, error) {
+	versions, err := store.List(component)
+	if err != nil {
+		return nil, err
+	}
+	index := &backupIndex{Versions: versions}
+	return index.findAt(component, at)
+}
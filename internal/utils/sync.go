@@ -0,0 +1,252 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"qs-tools/internal/manifest"
+)
+
+// SyncState 本地配置与远程最新备份的同步状态
+type SyncState string
+
+const (
+	// SyncInSync 本地配置与远程最新备份相同
+	SyncInSync SyncState = "synced"
+	// SyncAhead 本地有未备份的修改，远程在上一次同步后没有新的备份
+	SyncAhead SyncState = "ahead"
+	// SyncBehind 本地在上一次同步后没有修改，远程有新的备份
+	SyncBehind SyncState = "behind"
+	// SyncModified 本地与远程都有变化，或没有同步记录无法判断
+	SyncModified SyncState = "modified"
+	// SyncNoBackup 远程服务器上没有备份
+	SyncNoBackup SyncState = "no-backup"
+)
+
+// syncedPath 返回组件上一次同步的清单的保存路径
+func syncedPath(component string) (string, error) {
+	stateDir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "synced", component+".json"), nil
+}
+
+// LoadSynced 读取组件上一次同步（恢复或备份）的清单，没有同步记录时返回 nil
+func LoadSynced(component string) (*manifest.Manifest, error) {
+	path, err := syncedPath(component)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取同步记录失败: %v", err)
+	}
+	defer file.Close()
+
+	m, err := manifest.Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取同步记录失败: %v", err)
+	}
+	return m, nil
+}
+
+// saveSynced 保存恢复或备份的清单，用于判断本地文件在同步后是否有修改。
+// 模板渲染结果的哈希只在恢复时更新，清单中没有时沿用原同步记录中的值
+func saveSynced(m *manifest.Manifest) error {
+	path, err := syncedPath(m.Component)
+	if err != nil {
+		return err
+	}
+	if prev := renderedHashes(m.Component); len(prev) > 0 {
+		for i := range m.Files {
+			if f := &m.Files[i]; f.RenderedSHA256 == "" {
+				f.RenderedSHA256 = prev[f.Path]
+			}
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("保存同步记录失败: %v", err)
+	}
+
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("保存同步记录失败: %v", err)
+	}
+	return nil
+}
+
+// renderedHashes 返回同步记录中各模板渲染结果的哈希，没有同步记录时返回 nil
+func renderedHashes(component string) map[string]string {
+	synced, err := LoadSynced(component)
+	if err != nil || synced == nil {
+		return nil
+	}
+	hashes := make(map[string]string)
+	for _, f := range synced.Files {
+		if f.RenderedSHA256 != "" {
+			hashes[f.Path] = f.RenderedSHA256
+		}
+	}
+	return hashes
+}
+
+// CompareWithRemote 比较备份目标在当前系统上的文件与远程最新的备份，
+// 返回远程最新的备份版本（没有备份时为 nil）和同步状态
+func CompareWithRemote(component string, targets manifest.Targets) (*BackupMeta, SyncState, error) {
+	store := RemoteStore()
+	versions, err := store.List(component)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(versions) == 0 {
+		return nil, SyncNoBackup, nil
+	}
+	latest := &versions[len(versions)-1]
+
+	local, err := manifest.Build(component, targets, "")
+	if err != nil {
+		return latest, "", err
+	}
+
+	remoteHash := latest.TreeHash
+	if remoteHash == "" {
+		m, _, err := FetchManifest(store, component, latest.ID)
+		if err != nil {
+			return latest, "", err
+		}
+		remoteHash = m.TreeHash()
+	}
+
+	synced, syncErr := LoadSynced(component)
+	if synced != nil {
+		redactLocal(local, synced)
+	}
+
+	localHash := local.TreeHash()
+	if localHash == remoteHash {
+		return latest, SyncInSync, nil
+	}
+
+	if syncErr != nil || synced == nil {
+		return latest, SyncModified, syncErr
+	}
+	baseHash := synced.TreeHash()
+	switch {
+	case localHash == baseHash:
+		return latest, SyncBehind, nil
+	case remoteHash == baseHash:
+		return latest, SyncAhead, nil
+	default:
+		return latest, SyncModified, nil
+	}
+}
+
+// fileHashes 返回清单中各文件的哈希
+func fileHashes(m *manifest.Manifest) map[string]string {
+	hashes := make(map[string]string, len(m.Files))
+	for _, f := range m.Files {
+		hashes[f.Path] = f.SHA256
+	}
+	return hashes
+}
+
+// redactedLocalHashes 返回清单中脱敏的文件在本地的当前哈希，本地不存在的文件跳过
+func redactedLocalHashes(m *manifest.Manifest, targets manifest.Targets) map[string]string {
+	hashes := make(map[string]string)
+	for _, f := range m.Files {
+		if !f.Redacted {
+			continue
+		}
+		path, err := targets.Resolve(f.Path)
+		if err != nil {
+			continue
+		}
+		if sum, err := manifest.HashFile(path); err == nil {
+			hashes[f.Path] = sum
+		}
+	}
+	return hashes
+}
+
+// withLocalHashes 返回用于同步记录的清单副本，脱敏的文件记录其在本地的哈希 hashes
+func withLocalHashes(m *manifest.Manifest, hashes map[string]string) *manifest.Manifest {
+	synced := *m
+	synced.Files = append([]manifest.File(nil), m.Files...)
+	for i := range synced.Files {
+		f := &synced.Files[i]
+		if f.Redacted {
+			f.LocalSHA256 = hashes[f.Path]
+		}
+	}
+	return &synced
+}
+
+// redactLocal 将本地清单中同步后没有修改、但与备份内容不同的文件（脱敏或合并的文件）
+// 替换为同步记录中备份的哈希，使本地的密钥和合并的内容不会被当作与备份不同的修改
+func redactLocal(local, synced *manifest.Manifest) {
+	syncedFiles := make(map[string]manifest.File, len(synced.Files))
+	for _, f := range synced.Files {
+		syncedFiles[f.Path] = f
+	}
+	for i := range local.Files {
+		f := &local.Files[i]
+		if s, ok := syncedFiles[f.Path]; ok && s.LocalSHA256 != "" && s.LocalSHA256 == f.SHA256 {
+			f.SHA256 = s.SHA256
+			f.Size = s.Size
+		}
+	}
+}
+
+// syncedView 返回按同步记录替换了同步后没有修改的合并文件的清单副本，
+// merged 表示是否有文件被替换。没有同步记录时返回 m 本身
+func syncedView(m *manifest.Manifest) (view *manifest.Manifest, merged bool) {
+	synced, err := LoadSynced(m.Component)
+	if err != nil || synced == nil {
+		return m, false
+	}
+	copied := *m
+	copied.Files = append([]manifest.File(nil), m.Files...)
+	redactLocal(&copied, synced)
+	view = &copied
+	return view, view.TreeHash() != m.TreeHash()
+}
+
+// RecordLocalChanges 在恢复后修改了本地文件（如合并本地与备份的内容）时，
+// 在同步记录中记录这些文件在本地的新哈希，使其不会被当作同步后的本地修改。
+// paths 为文件在清单中的路径，没有同步记录或记录中没有的文件跳过
+func RecordLocalChanges(component string, targets manifest.Targets, paths ...string) error {
+	synced, err := LoadSynced(component)
+	if err != nil || synced == nil {
+		return err
+	}
+	for _, p := range paths {
+		for i := range synced.Files {
+			f := &synced.Files[i]
+			if f.Path != p {
+				continue
+			}
+			path, err := targets.Resolve(p)
+			if err != nil {
+				return err
+			}
+			sum, err := manifest.HashFile(path)
+			if err != nil {
+				return fmt.Errorf("计算 %s 的哈希失败: %v", p, err)
+			}
+			if sum == f.SHA256 {
+				sum = ""
+			}
+			f.LocalSHA256 = sum
+		}
+	}
+	return saveSynced(synced)
+}
@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/user"
+	"runtime"
+	"strings"
+	"text/template"
+
+	"qs-tools/internal/config"
+	"qs-tools/internal/manifest"
+)
+
+// TemplateData 渲染模板文件时可以使用的数据，如 {{.Hostname}}、{{.Vars.email}}
+type TemplateData struct {
+	Hostname string
+	OS       string
+	Arch     string
+	User     string
+	Home     string
+	// Vars 配置文件中的全局变量和组件变量
+	Vars map[string]string
+}
+
+// NewTemplateData 返回当前主机上渲染组件模板的数据
+func NewTemplateData(component string) (*TemplateData, error) {
+	data := &TemplateData{
+		OS:   runtime.GOOS,
+		Arch: runtime.GOARCH,
+		Vars: make(map[string]string),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		data.Hostname = hostname
+	}
+	if u, err := user.Current(); err == nil {
+		data.User = u.Username
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		data.Home = home
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range cfg.Vars {
+		data.Vars[k] = v
+	}
+	for k, v := range cfg.Component(component).Vars {
+		data.Vars[k] = v
+	}
+	return data, nil
+}
+
+// RenderTemplate 渲染模板内容，使用未定义的变量时返回错误
+func RenderTemplate(name string, content []byte, data *TemplateData) ([]byte, error) {
+	tmpl, err := template.New(name).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"env": os.Getenv}).
+		Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("解析模板 %s 失败: %v", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("渲染模板 %s 失败: %v", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// IsTemplate 判断归档中的文件是否为模板
+func IsTemplate(name string) bool {
+	return strings.HasSuffix(name, manifest.TemplateExt)
+}
+
+// renderGuard 恢复到备份目标原来的位置时，检查渲染出的文件在上一次同步后是否在本地被修改
+type renderGuard struct {
+	// synced 上一次同步时各模板渲染结果的哈希，键为模板在清单中的路径，为 nil 表示没有同步记录
+	synced map[string]string
+	// cr 冲突处理策略，为 nil 时覆盖本地修改并给出提示
+	cr *conflictResolver
+}
+
+// edited 判断渲染出的文件 path 是否在上一次同步后被本地修改过且与新的渲染结果不同。
+// 没有同步记录时与渲染结果不同的本地文件都视为有修改，与 findConflicts 一致
+func (g *renderGuard) edited(tmpl, path string, output []byte) bool {
+	local, err := os.ReadFile(path)
+	if err != nil || bytes.Equal(local, output) {
+		return false
+	}
+	if g.synced == nil {
+		return true
+	}
+	sum := sha256.Sum256(local)
+	return g.synced[tmpl] != hex.EncodeToString(sum[:])
+}
+
+// renderTemplates 按当前主机渲染清单中已恢复到本地的模板文件，渲染结果写入去掉扩展名的文件。
+// guard 不为 nil 时，在本地修改过的渲染结果按冲突处理策略处理。
+// 返回各模板渲染结果的哈希，键为模板在清单中的路径，记入同步记录
+func renderTemplates(m *manifest.Manifest, targets manifest.Targets, guard *renderGuard) (map[string]string, error) {
+	rendered := make(map[string]string)
+	var data *TemplateData
+	for _, f := range m.Files {
+		if !IsTemplate(f.Path) {
+			continue
+		}
+		if data == nil {
+			var err error
+			if data, err = NewTemplateData(m.Component); err != nil {
+				return nil, err
+			}
+		}
+
+		path, err := targets.Resolve(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取模板失败: %v", err)
+		}
+		output, err := RenderTemplate(f.Path, content, data)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(output)
+		rendered[f.Path] = hex.EncodeToString(sum[:])
+
+		name := strings.TrimSuffix(f.Path, manifest.TemplateExt)
+		outputPath := strings.TrimSuffix(path, manifest.TemplateExt)
+		if guard != nil && guard.edited(f.Path, outputPath, output) {
+			if guard.cr == nil {
+				fmt.Printf("⚠️ 覆盖本地修改: %s\n", name)
+			} else {
+				// 渲染结果的修改时间取模板的修改时间，供 newer-wins 策略比较
+				header := &tar.Header{Name: name, Size: int64(len(output)), Mode: int64(f.Mode)}
+				if info, err := os.Stat(path); err == nil {
+					header.ModTime = info.ModTime()
+				}
+				guard.cr.conflicts[name] = true
+				if outputPath, err = guard.cr.resolve(name, outputPath, header); err != nil {
+					return nil, err
+				}
+				if outputPath == "" {
+					continue
+				}
+			}
+		}
+
+		if err := writeFile(outputPath, bytes.NewReader(output), os.FileMode(f.Mode).Perm()); err != nil {
+			return nil, fmt.Errorf("写入文件失败: %v", err)
+		}
+		fmt.Printf("已渲染模板: %s\n", name)
+	}
+	return rendered, nil
+}
@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// ToolVersion 执行工具的版本命令并返回输出的第一行，工具未安装时返回空字符串
+func ToolVersion(name string, args ...string) string {
+	output, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return ""
+	}
+
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,4 @@
+package version
+
+// Version 程序版本号
+var Version = "v0.1.0"
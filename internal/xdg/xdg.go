@@ -0,0 +1,111 @@
+// Package xdg 按 XDG Base Directory 规范返回用户的配置、数据、状态和缓存目录。
+// 环境变量 XDG_CONFIG_HOME、XDG_DATA_HOME、XDG_STATE_HOME、XDG_CACHE_HOME
+// 设置为绝对路径时优先使用，未设置时 Windows 使用 %APPDATA% 和 %LOCALAPPDATA%，
+// 其他系统使用 ~/.config、~/.local/share、~/.local/state 和 ~/.cache
+package xdg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// ConfigHome 返回用户配置目录，Windows 为 %APPDATA%，其他系统为 ~/.config
+func ConfigHome() (string, error) {
+	if dir := fromEnv("XDG_CONFIG_HOME"); dir != "" {
+		return dir, nil
+	}
+	if runtime.GOOS == "windows" {
+		return windowsDir("APPDATA", "AppData", "Roaming")
+	}
+	return homeDir(".config")
+}
+
+// DataHome 返回用户数据目录，Windows 为 %LOCALAPPDATA%，其他系统为 ~/.local/share
+func DataHome() (string, error) {
+	if dir := fromEnv("XDG_DATA_HOME"); dir != "" {
+		return dir, nil
+	}
+	if runtime.GOOS == "windows" {
+		return windowsDir("LOCALAPPDATA", "AppData", "Local")
+	}
+	return homeDir(".local", "share")
+}
+
+// StateHome 返回用户状态目录，Windows 为 %LOCALAPPDATA%，其他系统为 ~/.local/state
+func StateHome() (string, error) {
+	if dir := fromEnv("XDG_STATE_HOME"); dir != "" {
+		return dir, nil
+	}
+	if runtime.GOOS == "windows" {
+		return windowsDir("LOCALAPPDATA", "AppData", "Local")
+	}
+	return homeDir(".local", "state")
+}
+
+// CacheHome 返回用户缓存目录，Windows 为 %LOCALAPPDATA%，其他系统为 ~/.cache
+func CacheHome() (string, error) {
+	if dir := fromEnv("XDG_CACHE_HOME"); dir != "" {
+		return dir, nil
+	}
+	if runtime.GOOS == "windows" {
+		return windowsDir("LOCALAPPDATA", "AppData", "Local")
+	}
+	return homeDir(".cache")
+}
+
+// LocalAppData 返回 Windows 的 %LOCALAPPDATA%，部分工具（如 Neovim）在 Windows 上的配置位于该目录
+func LocalAppData() (string, error) {
+	return windowsDir("LOCALAPPDATA", "AppData", "Local")
+}
+
+// AppData 返回 Windows 的 %APPDATA%
+func AppData() (string, error) {
+	return windowsDir("APPDATA", "AppData", "Roaming")
+}
+
+// Env 返回环境变量 name 中的目录，按规范忽略相对路径
+func Env(name string) string {
+	return fromEnv(name)
+}
+
+// WithLegacy 兼容早期版本使用的固定位置：path 不存在而 legacy 存在时返回 legacy，否则返回 path
+func WithLegacy(path, legacy string) string {
+	if path == legacy {
+		return path
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy
+	}
+	return path
+}
+
+// fromEnv 返回环境变量中的绝对路径，未设置或为相对路径时返回空字符串
+func fromEnv(name string) string {
+	dir := os.Getenv(name)
+	if dir == "" || !filepath.IsAbs(dir) {
+		return ""
+	}
+	return filepath.Clean(dir)
+}
+
+// windowsDir 返回 Windows 环境变量中的目录，未设置时使用用户主目录下的默认位置
+func windowsDir(env string, elem ...string) (string, error) {
+	if dir := fromEnv(env); dir != "" {
+		return dir, nil
+	}
+	return homeDir(elem...)
+}
+
+// homeDir 返回用户主目录下的路径
+func homeDir(elem ...string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户主目录失败: %v", err)
+	}
+	return filepath.Join(append([]string{home}, elem...)...), nil
+}
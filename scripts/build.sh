@@ -1,7 +1,7 @@
 #!/bin/bash
 
 # 设置版本号（从 version.go 中获取）
-VERSION=$(grep -oP 'var Version = "\K[^"]+' internal/cmd/version.go)
+VERSION=$(grep -oP 'var Version = "\K[^"]+' internal/version/version.go)
 
 # 设置构建目录
 BUILD_DIR="build"